@@ -0,0 +1,313 @@
+package lsp
+
+import (
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/android"
+	"hasseg.org/sanat/output/apple"
+	"hasseg.org/sanat/output/dump"
+	"hasseg.org/sanat/output/icu"
+	"hasseg.org/sanat/output/java"
+	"hasseg.org/sanat/output/windows"
+	"hasseg.org/sanat/parser"
+	"hasseg.org/sanat/preprocessing"
+	"hasseg.org/sanat/util"
+)
+
+// The kinds of lines in a translation file, by their indentation (as
+// in parser.parseTranslationSet().)
+type lineKind int
+
+const (
+	lineKindOther lineKind = iota
+	lineKindSection
+	lineKindKey
+	lineKindMetadata
+)
+
+func kindOfLine(line string) lineKind {
+	trimmedLine := strings.TrimSpace(line)
+	if len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#") {
+		return lineKindOther
+	}
+	switch len(util.LeadingWhitespace(line)) {
+	case 0:
+		return lineKindSection
+	case 2:
+		return lineKindKey
+	case 4:
+		return lineKindMetadata
+	}
+	return lineKindOther
+}
+
+var metadataKeys = []string{"platforms", "tags", "comment", "maxlength"}
+
+func isMetadataKey(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, metadataKey := range metadataKeys {
+		if lowerKey == metadataKey {
+			return true
+		}
+	}
+	return strings.HasPrefix(lowerKey, "maxlength.")
+}
+
+var platformNames = []string{"apple", "android", "windows", "web"}
+
+// Language codes offered for completion in addition to the ones already
+// used in the document.
+var commonLanguages = []string{
+	"ar", "da", "de", "en", "en-GB", "es", "fa", "fi", "fr", "he", "hi", "it", "ja", "ko", "nb",
+	"nl", "pl", "pt", "pt-BR", "ru", "sv", "th", "tr", "uk", "zh-Hans", "zh-Hant",
+}
+
+type document struct {
+	text  string
+	lines []string
+}
+
+func newDocument(text string) *document {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return &document{text: text, lines: lines}
+}
+
+func (d *document) line(lineNumber int) string {
+	if lineNumber < 0 || len(d.lines) <= lineNumber {
+		return ""
+	}
+	return d.lines[lineNumber]
+}
+
+// contentRange returns the range of the line without its indentation
+// and trailing whitespace.
+func (d *document) contentRange(lineNumber int) textRange {
+	line := strings.TrimRight(d.line(lineNumber), " \t")
+	indentation := util.LeadingWhitespace(line)
+	return textRange{
+		Start: position{Line: lineNumber, Character: utf16Length(indentation)},
+		End:   position{Line: lineNumber, Character: utf16Length(line)},
+	}
+}
+
+// keyLineNumber returns the line number of the translation key that the
+// line at lineNumber belongs to, or -1.
+func (d *document) keyLineNumber(lineNumber int) int {
+	for i := lineNumber; 0 <= i; i-- {
+		switch kindOfLine(d.line(i)) {
+		case lineKindKey:
+			return i
+		case lineKindSection:
+			return -1
+		}
+	}
+	return -1
+}
+
+// blockLineNumbers returns the line numbers of the metadata lines in the
+// translation block whose key is on the line at keyLineNumber.
+func (d *document) blockLineNumbers(keyLineNumber int) []int {
+	ret := make([]int, 0)
+	for i := keyLineNumber + 1; i < len(d.lines); i++ {
+		kind := kindOfLine(d.line(i))
+		if kind == lineKindKey || kind == lineKindSection {
+			break
+		}
+		if kind == lineKindMetadata {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// keyLineNumbers returns the line numbers where the translation key is
+// defined (more than one if the key is used in several sections.)
+func (d *document) keyLineNumbers(key string) []int {
+	ret := make([]int, 0)
+	for i, line := range d.lines {
+		if kindOfLine(line) == lineKindKey && strings.TrimSpace(line) == key {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+func metadataKeyAndValue(line string) (string, string, bool) {
+	separatorIndex := strings.Index(line, "=")
+	if separatorIndex == -1 {
+		return strings.TrimSpace(line), "", false
+	}
+	return strings.TrimSpace(line[:separatorIndex]), strings.TrimSpace(line[separatorIndex+1:]), true
+}
+
+func (d *document) parse(preprocessor preprocessing.Preprocessor, errorHandler parser.ParserErrorHandler) model.TranslationSet {
+	set, _ := parser.TranslationSetFromReader(strings.NewReader(d.text), preprocessor, errorHandler)
+	return set
+}
+
+func (d *document) diagnostics(preprocessor preprocessing.Preprocessor) []diagnostic {
+	ret := make([]diagnostic, 0)
+	d.parse(preprocessor, func(lineNumber int, message string) {
+		ret = append(ret, diagnostic{
+			Range:    d.contentRange(lineNumber - 1),
+			Severity: diagnosticSeverityError,
+			Source:   "sanat",
+			Message:  message,
+		})
+	})
+	return ret
+}
+
+func (d *document) completionItems(pos position, preprocessor preprocessing.Preprocessor) []completionItem {
+	ret := make([]completionItem, 0)
+	line := d.line(pos.Line)
+	linePrefix := line[:byteOffsetForCharacter(line, pos.Character)]
+	if len(util.LeadingWhitespace(linePrefix)) != 4 {
+		return ret
+	}
+	keyLineNumber := d.keyLineNumber(pos.Line)
+	if keyLineNumber == -1 {
+		return ret
+	}
+
+	// The metadata keys and languages already set in this block
+	existingKeys := make(map[string]bool)
+	for _, lineNumber := range d.blockLineNumbers(keyLineNumber) {
+		if lineNumber != pos.Line {
+			key, _, _ := metadataKeyAndValue(d.line(lineNumber))
+			existingKeys[strings.ToLower(key)] = true
+		}
+	}
+
+	key, value, hasSeparator := metadataKeyAndValue(linePrefix)
+	if hasSeparator {
+		if strings.ToLower(key) != "platforms" {
+			return ret
+		}
+		listedPlatforms := make(map[string]bool)
+		for _, platformName := range util.ComponentsFromCommaSeparatedList(value) {
+			listedPlatforms[strings.ToLower(platformName)] = true
+		}
+		for _, platformName := range platformNames {
+			if !listedPlatforms[platformName] {
+				ret = append(ret, completionItem{Label: platformName, Kind: completionItemKindEnumMember, Detail: "Platform"})
+			}
+		}
+		return ret
+	}
+
+	for _, metadataKey := range metadataKeys {
+		if !existingKeys[metadataKey] {
+			ret = append(ret, completionItem{Label: metadataKey, Kind: completionItemKindProperty, Detail: "Metadata"})
+		}
+	}
+	for _, language := range append(d.parse(preprocessor, nil).Languages, commonLanguages...) {
+		if !existingKeys[strings.ToLower(language)] {
+			existingKeys[strings.ToLower(language)] = true
+			ret = append(ret, completionItem{Label: language, Kind: completionItemKindValue, Detail: "Language"})
+		}
+	}
+	return ret
+}
+
+// StringForPlatform renders the segments the way they are written into
+// the output formats of the platform.
+func StringForPlatform(platform model.TranslationPlatform, segments []model.Segment) string {
+	switch platform {
+	case model.PlatformApple:
+		return apple.StringFromSegments(segments)
+	case model.PlatformAndroid:
+		return android.StringFromSegments(segments)
+	case model.PlatformWindows:
+		return windows.StringFromSegments(segments)
+	case model.PlatformJava:
+		return java.StringFromSegments(segments)
+	case model.PlatformWeb:
+		return icu.MessageFromSegments(segments)
+	}
+	return ""
+}
+
+// hover describes the value on the line at pos as it renders on each
+// platform that the translation is for.
+func (d *document) hover(pos position, preprocessor preprocessing.Preprocessor) *hover {
+	line := d.line(pos.Line)
+	if kindOfLine(line) != lineKindMetadata {
+		return nil
+	}
+	language, _, hasSeparator := metadataKeyAndValue(line)
+	if !hasSeparator || isMetadataKey(language) {
+		return nil
+	}
+	keyLineNumber := d.keyLineNumber(pos.Line)
+	if keyLineNumber == -1 {
+		return nil
+	}
+
+	// Parse just this value (and the platforms of the translation)
+	blockText := d.line(keyLineNumber) + "\n"
+	for _, lineNumber := range d.blockLineNumbers(keyLineNumber) {
+		key, _, _ := metadataKeyAndValue(d.line(lineNumber))
+		if strings.ToLower(key) == "platforms" {
+			blockText += d.line(lineNumber) + "\n"
+		}
+	}
+	blockText += line + "\n"
+	set, err := parser.TranslationSetFromReader(strings.NewReader(blockText), preprocessor, nil)
+	if err != nil {
+		return nil
+	}
+	translation := set.Sections[0].Translations[0]
+	value := translation.ValueForLanguage(language)
+	if value == nil {
+		return nil
+	}
+
+	contents := "```\n"
+	for _, platform := range []model.TranslationPlatform{model.PlatformApple, model.PlatformAndroid, model.PlatformWindows, model.PlatformJava, model.PlatformWeb} {
+		if translation.IsForPlatform(platform) {
+			contents += dump.StringForPlatform(platform) + ": " + StringForPlatform(platform, value.Segments) + "\n"
+		}
+	}
+	contents += "```\n"
+
+	valueRange := d.contentRange(pos.Line)
+	return &hover{Contents: markupContent{Kind: "markdown", Value: contents}, Range: &valueRange}
+}
+
+// keyAtPosition returns the translation key on the key line at pos.
+func (d *document) keyAtPosition(pos position) (string, bool) {
+	line := d.line(pos.Line)
+	if kindOfLine(line) != lineKindKey {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+func (d *document) definitions(uri string, pos position) []location {
+	ret := make([]location, 0)
+	key, isKey := d.keyAtPosition(pos)
+	if !isKey {
+		return ret
+	}
+	for _, lineNumber := range d.keyLineNumbers(key) {
+		ret = append(ret, location{URI: uri, Range: d.contentRange(lineNumber)})
+	}
+	return ret
+}
+
+func (d *document) renameEdits(pos position, newName string) []textEdit {
+	ret := make([]textEdit, 0)
+	key, isKey := d.keyAtPosition(pos)
+	if !isKey {
+		return ret
+	}
+	for _, lineNumber := range d.keyLineNumbers(key) {
+		ret = append(ret, textEdit{Range: d.contentRange(lineNumber), NewText: newName})
+	}
+	return ret
+}
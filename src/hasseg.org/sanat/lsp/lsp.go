@@ -0,0 +1,179 @@
+// Package lsp implements a Language Server Protocol server for editing
+// translation files: diagnostics for parser errors, completion of
+// languages, metadata keys and platforms, hovers showing how values are
+// rendered on each platform, and go-to-definition and renaming of
+// translation keys.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"hasseg.org/sanat/preprocessing"
+)
+
+// Server serves a single client (editor), communicating over a pair of
+// streams (usually stdin and stdout.)
+type Server struct {
+	Preprocessor preprocessing.Preprocessor
+
+	writer         io.Writer
+	documents      map[string]*document
+	isShuttingDown bool
+}
+
+func New(preprocessor preprocessing.Preprocessor) *Server {
+	return &Server{Preprocessor: preprocessor, documents: make(map[string]*document)}
+}
+
+// Serve handles the messages read from r, writing responses and
+// notifications into w, until the client sends the `exit` notification
+// or r ends.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.writer = w
+	reader := bufio.NewReader(r)
+	for {
+		content, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			s.respondWithError(nil, errorCodeParseError, err.Error())
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if msg.ID == nil {
+			s.handleNotification(msg)
+		} else {
+			s.handleRequest(msg)
+		}
+	}
+}
+
+func (s *Server) respond(id *json.RawMessage, result interface{}) {
+	writeMessage(s.writer, map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func (s *Server) respondWithError(id *json.RawMessage, code int, errorMessage string) {
+	writeMessage(s.writer, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   responseError{Code: code, Message: errorMessage},
+	})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	writeMessage(s.writer, map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []diagnostic) {
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+func (s *Server) setDocumentText(uri string, text string) {
+	doc := newDocument(text)
+	s.documents[uri] = doc
+	s.publishDiagnostics(uri, doc.diagnostics(s.Preprocessor))
+}
+
+func (s *Server) handleNotification(msg message) {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.setDocumentText(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil && 0 < len(params.ContentChanges) {
+			// We only support full document sync, so the last change has
+			// the whole text
+			s.setDocumentText(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.URI)
+			s.publishDiagnostics(params.TextDocument.URI, []diagnostic{})
+		}
+	}
+}
+
+func (s *Server) handleRequest(msg message) {
+	if s.isShuttingDown && msg.Method != "shutdown" {
+		s.respondWithError(msg.ID, errorCodeInvalidRequest, "The server is shutting down")
+		return
+	}
+
+	switch msg.Method {
+	case "initialize":
+		s.respond(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // Full
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{",", " "}},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+				"renameProvider":     true,
+			},
+			"serverInfo": map[string]interface{}{"name": "sanat"},
+		})
+	case "shutdown":
+		s.isShuttingDown = true
+		s.respond(msg.ID, nil)
+	case "textDocument/completion", "textDocument/hover", "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.respondWithError(msg.ID, errorCodeInvalidParams, err.Error())
+			return
+		}
+		doc, exists := s.documents[params.TextDocument.URI]
+		if !exists {
+			s.respond(msg.ID, nil)
+			return
+		}
+		switch msg.Method {
+		case "textDocument/completion":
+			s.respond(msg.ID, doc.completionItems(params.Position, s.Preprocessor))
+		case "textDocument/hover":
+			if h := doc.hover(params.Position, s.Preprocessor); h != nil {
+				s.respond(msg.ID, h)
+			} else {
+				s.respond(msg.ID, nil)
+			}
+		case "textDocument/definition":
+			s.respond(msg.ID, doc.definitions(params.TextDocument.URI, params.Position))
+		}
+	case "textDocument/rename":
+		var params renameParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.respondWithError(msg.ID, errorCodeInvalidParams, err.Error())
+			return
+		}
+		doc, exists := s.documents[params.TextDocument.URI]
+		if !exists {
+			s.respond(msg.ID, nil)
+			return
+		}
+		newName := params.NewName
+		if len(newName) == 0 || strings.TrimSpace(newName) != newName || strings.ContainsAny(newName, "\r\n") || strings.HasPrefix(newName, "#") {
+			s.respondWithError(msg.ID, errorCodeRequestFailed, "Invalid translation key: '"+newName+"'")
+			return
+		}
+		edits := doc.renameEdits(params.Position, newName)
+		if len(edits) == 0 {
+			s.respondWithError(msg.ID, errorCodeRequestFailed, "Only translation keys can be renamed")
+			return
+		}
+		s.respond(msg.ID, workspaceEdit{Changes: map[string][]textEdit{params.TextDocument.URI: edits}})
+	default:
+		s.respondWithError(msg.ID, errorCodeMethodNotFound, "Unsupported method: "+msg.Method)
+	}
+}
@@ -0,0 +1,204 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/preprocessing"
+)
+
+const testURI = "file:///tmp/test.sanat"
+
+const testDocument = `=== Login ===
+
+  LoginView.Title
+    platforms = apple, web
+    en = Log in, {s}!
+    fi = Kirjaudu %
+
+  Oops
+    en = Hello
+Unindented
+    fi = ` + "\U0001F600" + `
+
+  LoginView.Title
+    en = Again
+`
+
+// session sends the given requests/notifications (as JSON values) to a
+// new server, and returns the messages it sent back.
+func session(t *testing.T, messages ...string) []map[string]interface{} {
+	input := ""
+	for _, msg := range messages {
+		input += fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	var output bytes.Buffer
+	err := New(preprocessing.NewNoOpPreprocessor()).Serve(bytes.NewBufferString(input), &output)
+	assert.Nil(t, err, "")
+
+	ret := make([]map[string]interface{}, 0)
+	reader := bufio.NewReader(&output)
+	for {
+		content, err := readMessage(reader)
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err, "")
+		var msg map[string]interface{}
+		assert.Nil(t, json.Unmarshal(content, &msg), "")
+		ret = append(ret, msg)
+	}
+	return ret
+}
+
+func didOpen(text string) string {
+	textJSON, _ := json.Marshal(text)
+	return `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"` + testURI + `","languageId":"sanat","version":1,"text":` + string(textJSON) + `}}}`
+}
+
+func positionRequest(id int, method string, line int, character int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"%s","params":{"textDocument":{"uri":"%s"},"position":{"line":%d,"character":%d}}}`,
+		id, method, testURI, line, character)
+}
+
+func toJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func labels(result interface{}) []string {
+	ret := make([]string, 0)
+	for _, item := range result.([]interface{}) {
+		ret = append(ret, item.(map[string]interface{})["label"].(string))
+	}
+	return ret
+}
+
+func TestUTF16Positions(t *testing.T) {
+	assert.Equal(t, 3, utf16Length("aäb"), "")
+	assert.Equal(t, 4, utf16Length("a\U0001F600b"), "")
+	assert.Equal(t, 1, byteOffsetForCharacter("a\U0001F600b", 1), "")
+	assert.Equal(t, 5, byteOffsetForCharacter("a\U0001F600b", 3), "")
+	assert.Equal(t, 6, byteOffsetForCharacter("a\U0001F600b", 10), "")
+}
+
+func TestLifecycle(t *testing.T) {
+	responses := session(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"textDocument/formatting","params":{}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover","params":{}}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`)
+
+	assert.Equal(t, 4, len(responses), "Nothing is handled after exit")
+	capabilities := responses[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Equal(t, true, capabilities["hoverProvider"], "")
+	assert.Equal(t, true, capabilities["renameProvider"], "")
+	assert.Equal(t, float64(errorCodeMethodNotFound), responses[1]["error"].(map[string]interface{})["code"], "")
+	assert.Nil(t, responses[2]["result"], "")
+	assert.Equal(t, float64(errorCodeInvalidRequest), responses[3]["error"].(map[string]interface{})["code"], "")
+}
+
+func TestDiagnostics(t *testing.T) {
+	responses := session(t,
+		didOpen(testDocument),
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"`+testURI+`","version":2},"contentChanges":[{"text":"  Fine\n    en = Fine\n"}]}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"`+testURI+`"}}}`)
+
+	assert.Equal(t, 3, len(responses), "")
+	assert.Equal(t, "textDocument/publishDiagnostics", responses[0]["method"], "")
+	params := responses[0]["params"].(map[string]interface{})
+	assert.Equal(t, testURI, params["uri"], "")
+	diagnostics := params["diagnostics"].([]interface{})
+	assert.Equal(t, 1, len(diagnostics), "")
+	assert.Equal(t,
+		`{"message":"Unknown un-indented line 'Unindented' — Prepend with === if section; indent if translation key.","range":{"end":{"character":10,"line":9},"start":{"character":0,"line":9}},"severity":1,"source":"sanat"}`,
+		toJSON(diagnostics[0]), "")
+
+	assert.Equal(t, 0, len(responses[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})), "")
+	assert.Equal(t, 0, len(responses[2]["params"].(map[string]interface{})["diagnostics"].([]interface{})), "Diagnostics are cleared on close")
+}
+
+func TestCompletion(t *testing.T) {
+	responses := session(t,
+		didOpen(testDocument+"  New\n    \n    platforms = apple, \n"),
+		positionRequest(1, "textDocument/completion", 15, 4),
+		positionRequest(2, "textDocument/completion", 16, 23),
+		positionRequest(3, "textDocument/completion", 4, 4),
+		positionRequest(4, "textDocument/completion", 4, 9),
+		positionRequest(5, "textDocument/completion", 2, 2))
+
+	completions := labels(responses[1]["result"])
+	assert.Equal(t, []string{"tags", "comment", "maxlength", "en", "fi", "ar", "da"}, completions[:7], "Existing metadata keys are not offered; languages in the document come first")
+	assert.Equal(t, 1, countOf(completions, "fi"), "")
+
+	assert.Equal(t, []string{"android", "windows", "web"}, labels(responses[2]["result"]), "")
+
+	completions = labels(responses[3]["result"])
+	assert.Equal(t, []string{"tags", "comment", "maxlength", "en"}, completions[:4], "The language on the line being completed is offered")
+	assert.Equal(t, 0, countOf(completions, "fi"), "")
+
+	assert.Equal(t, []string{}, labels(responses[4]["result"]), "No completions for values")
+	assert.Equal(t, []string{}, labels(responses[5]["result"]), "No completions for keys")
+}
+
+func countOf(strings []string, s string) int {
+	ret := 0
+	for _, candidate := range strings {
+		if candidate == s {
+			ret++
+		}
+	}
+	return ret
+}
+
+func TestHover(t *testing.T) {
+	responses := session(t,
+		didOpen(testDocument),
+		positionRequest(1, "textDocument/hover", 4, 10),
+		positionRequest(2, "textDocument/hover", 5, 10),
+		positionRequest(3, "textDocument/hover", 8, 10),
+		positionRequest(4, "textDocument/hover", 3, 10),
+		positionRequest(5, "textDocument/hover", 2, 4))
+
+	contents := func(response map[string]interface{}) string {
+		return response["result"].(map[string]interface{})["contents"].(map[string]interface{})["value"].(string)
+	}
+	assert.Equal(t, "```\nApple: Log in, %@!\nWeb: Log in, {0}!\n```\n", contents(responses[1]), "Only the translation's platforms are listed")
+	assert.Equal(t, "```\nApple: Kirjaudu %%\nWeb: Kirjaudu %\n```\n", contents(responses[2]), "")
+	assert.Equal(t, "```\nApple: Hello\nAndroid: Hello\nWindows: Hello\nJava: Hello\nWeb: Hello\n```\n", contents(responses[3]), "")
+	assert.Nil(t, responses[4]["result"], "No hover for metadata")
+	assert.Nil(t, responses[5]["result"], "No hover for keys")
+}
+
+func TestDefinitionAndRename(t *testing.T) {
+	responses := session(t,
+		didOpen(testDocument),
+		positionRequest(1, "textDocument/definition", 12, 5),
+		positionRequest(2, "textDocument/definition", 4, 5),
+		`{"jsonrpc":"2.0","id":3,"method":"textDocument/rename","params":{"textDocument":{"uri":"`+testURI+`"},"position":{"line":2,"character":3},"newName":"LoginView.Heading"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/rename","params":{"textDocument":{"uri":"`+testURI+`"},"position":{"line":2,"character":3},"newName":" Bad"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"textDocument/rename","params":{"textDocument":{"uri":"`+testURI+`"},"position":{"line":4,"character":3},"newName":"x"}}`)
+
+	assert.Equal(t,
+		`[{"range":{"end":{"character":17,"line":2},"start":{"character":2,"line":2}},"uri":"file:///tmp/test.sanat"},`+
+			`{"range":{"end":{"character":17,"line":12},"start":{"character":2,"line":12}},"uri":"file:///tmp/test.sanat"}]`,
+		toJSON(responses[1]["result"]), "All definitions of the key (in all sections)")
+	assert.Equal(t, "[]", toJSON(responses[2]["result"]), "")
+
+	assert.Equal(t,
+		`{"changes":{"file:///tmp/test.sanat":[`+
+			`{"newText":"LoginView.Heading","range":{"end":{"character":17,"line":2},"start":{"character":2,"line":2}}},`+
+			`{"newText":"LoginView.Heading","range":{"end":{"character":17,"line":12},"start":{"character":2,"line":12}}}]}}`,
+		toJSON(responses[3]["result"]), "")
+	assert.NotNil(t, responses[4]["error"], "Invalid key")
+	assert.NotNil(t, responses[5]["error"], "Not a key")
+}
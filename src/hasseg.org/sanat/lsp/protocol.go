@@ -0,0 +1,200 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The subset of the Language Server Protocol types that we use.
+// https://microsoft.github.io/language-server-protocol/specification
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	errorCodeParseError     = -32700
+	errorCodeInvalidRequest = -32600
+	errorCodeInvalidParams  = -32602
+	errorCodeMethodNotFound = -32601
+	errorCodeRequestFailed  = -32803
+)
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string    `json:"uri"`
+	Range textRange `json:"range"`
+}
+
+type textEdit struct {
+	Range   textRange `json:"range"`
+	NewText string    `json:"newText"`
+}
+
+type workspaceEdit struct {
+	Changes map[string][]textEdit `json:"changes"`
+}
+
+const diagnosticSeverityError = 1
+
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+const (
+	completionItemKindProperty   = 10
+	completionItemKindValue      = 12
+	completionItemKindEnumMember = 20
+)
+
+type completionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    *textRange    `json:"range,omitempty"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type didOpenTextDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeTextDocumentParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didCloseTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type renameParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+	NewName      string                 `json:"newName"`
+}
+
+// readMessage reads one message (a header part followed by a JSON
+// content part) from r, and returns its content part.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			break
+		}
+		separatorIndex := strings.Index(line, ":")
+		if separatorIndex == -1 {
+			continue
+		}
+		if strings.ToLower(strings.TrimSpace(line[:separatorIndex])) == "content-length" {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(line[separatorIndex+1:]))
+			if err != nil {
+				return nil, errors.New("Invalid Content-Length header: " + line)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, errors.New("Missing Content-Length header")
+	}
+
+	content := make([]byte, contentLength)
+	_, err := io.ReadFull(r, content)
+	return content, err
+}
+
+// writeMessage writes v as a message with a header part into w.
+func writeMessage(w io.Writer, v interface{}) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	return err
+}
+
+// Positions in the protocol count UTF-16 code units, while Go strings
+// are indexed by bytes.
+
+func utf16Length(s string) int {
+	ret := 0
+	for _, r := range s {
+		if 0x10000 <= r {
+			ret += 2
+		} else {
+			ret++
+		}
+	}
+	return ret
+}
+
+func byteOffsetForCharacter(s string, character int) int {
+	units := 0
+	for i, r := range s {
+		if character <= units {
+			return i
+		}
+		if 0x10000 <= r {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,271 @@
+// Package jsondoc has the document types of the `json` output format
+// (also accepted as an input file), and the conversions between them
+// and the model.
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"hasseg.org/sanat/model"
+)
+
+// SchemaVersion is the version of the JSON Schema (see
+// misc/JSONSchema in the Sanat repository) that the output conforms to.
+// It is bumped whenever the structure changes (even when only new
+// values are allowed), so that documents written by newer versions
+// aren't validated against an older schema that would reject them.
+// Documents of older versions can still be read.
+const SchemaVersion = 3
+
+// Document is the root object of the JSON documents.
+type Document struct {
+	Version   int       `json:"version"`
+	Languages []string  `json:"languages"`
+	Sections  []Section `json:"sections"`
+}
+
+type Section struct {
+	Name         string        `json:"name"`
+	Translations []Translation `json:"translations"`
+}
+
+type Translation struct {
+	Key                string         `json:"key"`
+	Platforms          []string       `json:"platforms,omitempty"`
+	Tags               []string       `json:"tags,omitempty"`
+	Comment            string         `json:"comment,omitempty"`
+	MaxLength          int            `json:"maxLength,omitempty"`
+	PlatformMaxLengths map[string]int `json:"platformMaxLengths,omitempty"`
+	Values             []Value        `json:"values"`
+}
+
+type Value struct {
+	Language string    `json:"language"`
+	Segments []Segment `json:"segments"`
+}
+
+// Segment is either a text segment (only Text is set) or a format
+// specifier segment (DataType is set.)
+type Segment struct {
+	Text             *string `json:"text,omitempty"`
+	DataType         string  `json:"dataType,omitempty"`
+	NumberOfDecimals *int    `json:"numberOfDecimals,omitempty"`
+	OrderIndex       int     `json:"orderIndex,omitempty"`
+}
+
+func StringForDataType(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "string"
+	case model.DataTypeInteger:
+		return "integer"
+	case model.DataTypeFloat:
+		return "float"
+	case model.DataTypeObject:
+		return "object"
+	}
+	return "??"
+}
+
+func StringForPlatform(platform model.TranslationPlatform) string {
+	switch platform {
+	case model.PlatformApple:
+		return "Apple"
+	case model.PlatformAndroid:
+		return "Android"
+	case model.PlatformWindows:
+		return "Windows"
+	case model.PlatformJava:
+		return "Java"
+	case model.PlatformWeb:
+		return "Web"
+	}
+	return "??"
+}
+
+func SegmentForFormatSpecifier(segment model.FormatSpecifierSegment) Segment {
+	ret := Segment{DataType: StringForDataType(segment.DataType)}
+	if segment.DataType == model.DataTypeFloat && 0 <= segment.NumberOfDecimals {
+		numberOfDecimals := segment.NumberOfDecimals
+		ret.NumberOfDecimals = &numberOfDecimals
+	}
+	if 0 < segment.SemanticOrderIndex {
+		ret.OrderIndex = segment.SemanticOrderIndex
+	}
+	return ret
+}
+
+func DocumentForTranslationSet(set model.TranslationSet) Document {
+	ret := Document{
+		Version:   SchemaVersion,
+		Languages: append(make([]string, 0), set.Languages...),
+		Sections:  make([]Section, 0),
+	}
+	for _, section := range set.Sections {
+		jsonSection := Section{Name: section.Name, Translations: make([]Translation, 0)}
+		for _, translation := range section.Translations {
+			jsonTranslation := Translation{
+				Key:     translation.Key,
+				Tags:    translation.Tags,
+				Comment: translation.Comment,
+				Values:  make([]Value, 0),
+			}
+			for _, platform := range translation.Platforms {
+				jsonTranslation.Platforms = append(jsonTranslation.Platforms, StringForPlatform(platform))
+			}
+			jsonTranslation.MaxLength = translation.MaxLength
+			for platform, maxLength := range translation.PlatformMaxLengths {
+				if jsonTranslation.PlatformMaxLengths == nil {
+					jsonTranslation.PlatformMaxLengths = make(map[string]int)
+				}
+				jsonTranslation.PlatformMaxLengths[StringForPlatform(platform)] = maxLength
+			}
+			for _, value := range translation.Values {
+				jsonValue := Value{Language: value.Language, Segments: make([]Segment, 0)}
+				for _, segment := range value.Segments {
+					switch segment.(type) {
+					case model.TextSegment:
+						text := segment.(model.TextSegment).Text
+						jsonValue.Segments = append(jsonValue.Segments, Segment{Text: &text})
+					case model.FormatSpecifierSegment:
+						jsonValue.Segments = append(jsonValue.Segments, SegmentForFormatSpecifier(segment.(model.FormatSpecifierSegment)))
+					}
+				}
+				jsonTranslation.Values = append(jsonTranslation.Values, jsonValue)
+			}
+			jsonSection.Translations = append(jsonSection.Translations, jsonTranslation)
+		}
+		ret.Sections = append(ret.Sections, jsonSection)
+	}
+	return ret
+}
+
+func DataTypeForString(s string) (model.TranslationFormatDataType, error) {
+	for _, dataType := range []model.TranslationFormatDataType{
+		model.DataTypeObject,
+		model.DataTypeInteger,
+		model.DataTypeString,
+		model.DataTypeFloat,
+	} {
+		if StringForDataType(dataType) == s {
+			return dataType, nil
+		}
+	}
+	return model.DataTypeNone, errors.New("Unknown data type: '" + s + "'")
+}
+
+func PlatformForString(s string) (model.TranslationPlatform, error) {
+	for _, platform := range []model.TranslationPlatform{
+		model.PlatformApple,
+		model.PlatformAndroid,
+		model.PlatformWindows,
+		model.PlatformJava,
+		model.PlatformWeb,
+	} {
+		if StringForPlatform(platform) == s {
+			return platform, nil
+		}
+	}
+	return model.PlatformNone, errors.New("Unknown platform value: '" + s + "'")
+}
+
+func segmentForJSONSegment(jsonSegment Segment) (model.Segment, error) {
+	if jsonSegment.Text != nil {
+		return model.NewTextSegment(*jsonSegment.Text), nil
+	}
+	if len(jsonSegment.DataType) == 0 {
+		return nil, errors.New("Segment has neither 'text' nor 'dataType'")
+	}
+	dataType, err := DataTypeForString(jsonSegment.DataType)
+	if err != nil {
+		return nil, err
+	}
+	numDecimals := -1
+	if jsonSegment.NumberOfDecimals != nil {
+		numDecimals = *jsonSegment.NumberOfDecimals
+	}
+	semanticOrderIndex := -1
+	if 0 < jsonSegment.OrderIndex {
+		semanticOrderIndex = jsonSegment.OrderIndex
+	}
+	return model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), nil
+}
+
+// TranslationSetForDocument is the inverse of DocumentForTranslationSet.
+func TranslationSetForDocument(document Document) (model.TranslationSet, error) {
+	set := model.NewTranslationSet()
+	if document.Version < 1 || SchemaVersion < document.Version {
+		return set, fmt.Errorf("Unsupported JSON version %d (expected 1–%d)", document.Version, SchemaVersion)
+	}
+	for _, language := range document.Languages {
+		set.AddLanguage(language)
+	}
+	for _, jsonSection := range document.Sections {
+		section := set.AddSection(jsonSection.Name)
+		for _, jsonTranslation := range jsonSection.Translations {
+			translation := section.AddTranslation(jsonTranslation.Key)
+			translation.Tags = jsonTranslation.Tags
+			translation.Comment = jsonTranslation.Comment
+			for _, platformString := range jsonTranslation.Platforms {
+				platform, err := PlatformForString(platformString)
+				if err != nil {
+					return set, err
+				}
+				translation.Platforms = append(translation.Platforms, platform)
+			}
+			translation.MaxLength = jsonTranslation.MaxLength
+			for platformString, maxLength := range jsonTranslation.PlatformMaxLengths {
+				platform, err := PlatformForString(platformString)
+				if err != nil {
+					return set, err
+				}
+				if translation.PlatformMaxLengths == nil {
+					translation.PlatformMaxLengths = make(map[model.TranslationPlatform]int)
+				}
+				translation.PlatformMaxLengths[platform] = maxLength
+			}
+			for _, jsonValue := range jsonTranslation.Values {
+				segments := make([]model.Segment, 0, len(jsonValue.Segments))
+				for _, jsonSegment := range jsonValue.Segments {
+					segment, err := segmentForJSONSegment(jsonSegment)
+					if err != nil {
+						return set, errors.New("Translation '" + jsonTranslation.Key + "': " + err.Error())
+					}
+					segments = append(segments, segment)
+				}
+				translation.AddValue(jsonValue.Language, segments)
+				set.AddLanguage(jsonValue.Language)
+			}
+		}
+	}
+	return set, nil
+}
+
+// ReadDocument decodes a Document from JSON. If the input is not valid
+// JSON, the returned line number tells where the problem is (it is 0
+// otherwise.)
+func ReadDocument(reader io.Reader) (Document, int, error) {
+	var document Document
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return document, 0, err
+	}
+
+	err = json.Unmarshal(data, &document)
+	offset := int64(-1)
+	switch err.(type) {
+	case *json.SyntaxError:
+		offset = err.(*json.SyntaxError).Offset
+	case *json.UnmarshalTypeError:
+		offset = err.(*json.UnmarshalTypeError).Offset
+	}
+	if 0 <= offset && offset <= int64(len(data)) {
+		return document, 1 + bytes.Count(data[:offset], []byte("\n")), err
+	}
+	return document, 0, err
+}
@@ -0,0 +1,81 @@
+package jsondoc_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/model/jsondoc"
+	"hasseg.org/sanat/parser"
+	"hasseg.org/sanat/preprocessing"
+	"hasseg.org/sanat/util"
+)
+
+func TestJSONSegmentForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		b, _ := json.Marshal(jsondoc.SegmentForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex)))
+		return string(b)
+	}
+
+	// Data types
+	assert.Equal(t, `{"dataType":"object"}`, val(model.DataTypeObject, -1, -1), "")
+	assert.Equal(t, `{"dataType":"string"}`, val(model.DataTypeString, -1, -1), "")
+	assert.Equal(t, `{"dataType":"float"}`, val(model.DataTypeFloat, -1, -1), "")
+	assert.Equal(t, `{"dataType":"integer"}`, val(model.DataTypeInteger, -1, -1), "")
+
+	// Semantic order index
+	assert.Equal(t, `{"dataType":"float","orderIndex":3}`, val(model.DataTypeFloat, -1, 3), "")
+
+	// Decimal count
+	assert.Equal(t, `{"dataType":"float","numberOfDecimals":0}`, val(model.DataTypeFloat, 0, -1), "")
+	assert.Equal(t, `{"dataType":"float","numberOfDecimals":2,"orderIndex":1}`, val(model.DataTypeFloat, 2, 1), "")
+	assert.Equal(t, `{"dataType":"integer"}`, val(model.DataTypeInteger, 2, -1), "Decimal count is only for floats")
+}
+
+func TestRoundTrip(t *testing.T) {
+	set, _ := parser.TranslationSetFromFile("../../output/testdata/comprehensive.sanat", preprocessing.NewNoOpPreprocessor(), nil)
+
+	document, lineNumber, err := jsondoc.ReadDocument(strings.NewReader(util.IndentedJSON(jsondoc.DocumentForTranslationSet(set))))
+	assert.Nil(t, err, "")
+	assert.Equal(t, 0, lineNumber, "")
+
+	readSet, err := jsondoc.TranslationSetForDocument(document)
+	assert.Nil(t, err, "")
+	assert.Equal(t, set, readSet, "")
+}
+
+func TestReadingInvalidInput(t *testing.T) {
+	assertError := func(input string, expectedLineNumber int, expectedErrorMessageMatch string) {
+		document, lineNumber, err := jsondoc.ReadDocument(strings.NewReader(input))
+		if err == nil {
+			_, err = jsondoc.TranslationSetForDocument(document)
+		}
+		if assert.NotNil(t, err, input) {
+			assert.Equal(t, expectedLineNumber, lineNumber, input)
+			assert.Contains(t, err.Error(), expectedErrorMessageMatch, input)
+		}
+	}
+
+	assertError("{\n\"version\": 1,\n\"languages\": [,]}", 3, "invalid character")
+	assertError(`{"version": 99}`, 0, "Unsupported JSON version")
+	assertError(`{"version": 0}`, 0, "Unsupported JSON version")
+	assertError(`{"version": 1, "sections": [{"translations": [{"key": "Foo", "platforms": ["Amiga"]}]}]}`,
+		0, "Unknown platform value")
+	assertError(`{"version": 1, "sections": [{"translations": [{"key": "Foo", "values": [{"language": "en", "segments": [{"dataType": "complex"}]}]}]}]}`,
+		0, "Unknown data type")
+	assertError(`{"version": 1, "sections": [{"translations": [{"key": "Foo", "values": [{"language": "en", "segments": [{}]}]}]}]}`,
+		0, "neither 'text' nor 'dataType'")
+}
+
+func TestReadingOlderVersion(t *testing.T) {
+	document, _, err := jsondoc.ReadDocument(strings.NewReader(`{"version": 1, "languages": ["en"], "sections": [{"name": "", "translations": [{"key": "Foo", "values": [{"language": "en", "segments": [{"text": "Bar"}]}]}]}]}`))
+	assert.Nil(t, err, "")
+	set, err := jsondoc.TranslationSetForDocument(document)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "Foo", set.Sections[0].Translations[0].Key, "")
+}
@@ -1,6 +1,8 @@
 package model
 
-import ()
+import (
+	"strconv"
+)
 
 // TranslationFormatDataType is the “enum” type for format
 // specifier data types.
@@ -24,6 +26,7 @@ const (
 	PlatformAndroid
 	PlatformWindows
 	PlatformJava
+	PlatformWeb
 )
 
 // TextSegment is a piece of a translation string value
@@ -40,6 +43,23 @@ type FormatSpecifierSegment struct {
 	NumberOfDecimals   int
 }
 
+// ArgumentIndex returns the 0-based index of the argument that this
+// format specifier refers to, given its 0-based position among the
+// format specifiers in its translation value.
+func (segment FormatSpecifierSegment) ArgumentIndex(position int) int {
+	if 0 < segment.SemanticOrderIndex {
+		return segment.SemanticOrderIndex - 1
+	}
+	return position
+}
+
+// ArgumentName returns the name used for the argument with the given
+// 0-based index in output formats that have named placeholders or
+// parameters (e.g. "arg1".)
+func ArgumentName(argumentIndex int) string {
+	return "arg" + strconv.Itoa(argumentIndex+1)
+}
+
 // Segment is the “union” type for translation string value
 // segments.
 type Segment interface{}
@@ -51,15 +71,46 @@ type TranslationValue struct {
 	Segments []Segment
 }
 
+// Arguments returns the format specifiers in the value, ordered by the
+// index of the argument that they refer to (see ArgumentIndex). If
+// several specifiers refer to the same argument, the first one is used;
+// argument indexes that no specifier refers to are filled in with
+// DataTypeNone specifiers.
+func (value TranslationValue) Arguments() []FormatSpecifierSegment {
+	ret := make([]FormatSpecifierSegment, 0)
+	isSet := make([]bool, 0)
+	position := 0
+	for _, segment := range value.Segments {
+		specifier, ok := segment.(FormatSpecifierSegment)
+		if !ok {
+			continue
+		}
+		index := specifier.ArgumentIndex(position)
+		position++
+		for len(ret) <= index {
+			ret = append(ret, NewFormatSpecifierSegment(DataTypeNone, -1, -1))
+			isSet = append(isSet, false)
+		}
+		if !isSet[index] {
+			ret[index] = specifier
+			isSet[index] = true
+		}
+	}
+	return ret
+}
+
 // Translation is a unique localizable string containing
 // values for N languages. It can be limited only to specific
-// platforms.
+// platforms, and its values to a maximum length (0 for no limit),
+// optionally different on some platforms.
 type Translation struct {
-	Key       string
-	Values    []TranslationValue
-	Platforms []TranslationPlatform
-	Tags      []string
-	Comment   string
+	Key                string
+	Values             []TranslationValue
+	Platforms          []TranslationPlatform
+	Tags               []string
+	Comment            string
+	MaxLength          int
+	PlatformMaxLengths map[TranslationPlatform]int
 }
 
 // TranslationSection is a named group of Translations.
@@ -68,14 +119,46 @@ type TranslationSection struct {
 	Translations []Translation
 }
 
-// TranslationSet is a set of TranslationSections.
+// TranslationSet is a set of TranslationSections. Languages are
+// listed in the order in which they first appear.
 type TranslationSet struct {
 	Sections  []TranslationSection
-	Languages map[string]bool
+	Languages []string
 }
 
 func NewTranslationSet() TranslationSet {
-	return TranslationSet{Languages: make(map[string]bool)}
+	return TranslationSet{Languages: make([]string, 0)}
+}
+
+func (set *TranslationSet) AddLanguage(language string) {
+	if !set.HasLanguage(language) {
+		set.Languages = append(set.Languages, language)
+	}
+}
+
+func (set TranslationSet) HasLanguage(language string) bool {
+	for _, l := range set.Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderLanguages moves the given languages (those of them that are in
+// the set) to the front of set.Languages, in the given order. The rest
+// keep their existing relative order.
+func (set *TranslationSet) OrderLanguages(order []string) {
+	ordered := NewTranslationSet()
+	for _, language := range order {
+		if set.HasLanguage(language) {
+			ordered.AddLanguage(language)
+		}
+	}
+	for _, language := range set.Languages {
+		ordered.AddLanguage(language)
+	}
+	set.Languages = ordered.Languages
 }
 
 func (set *TranslationSet) AddSection(name string) *TranslationSection {
@@ -102,6 +185,10 @@ func (translation Translation) ValueForLanguage(language string) *TranslationVal
 	return nil
 }
 
+// IsForPlatform returns whether the translation should be written to the
+// output files for the given platform. Output formats that aren't for any
+// particular platform ask about PlatformNone, so translations limited to
+// some platforms are left out of them.
 func (translation Translation) IsForPlatform(givenPlatform TranslationPlatform) bool {
 	if len(translation.Platforms) == 0 {
 		return true
@@ -114,6 +201,15 @@ func (translation Translation) IsForPlatform(givenPlatform TranslationPlatform)
 	return false
 }
 
+// MaxLengthForPlatform returns the maximum length of the values on the
+// given platform (0 for no limit.)
+func (translation Translation) MaxLengthForPlatform(platform TranslationPlatform) int {
+	if maxLength, ok := translation.PlatformMaxLengths[platform]; ok {
+		return maxLength
+	}
+	return translation.MaxLength
+}
+
 func NewTextSegment(text string) TextSegment {
 	return TextSegment{Text: text}
 }
@@ -0,0 +1,76 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+)
+
+func TestLanguagesKeepFirstAppearanceOrder(t *testing.T) {
+	set := model.NewTranslationSet()
+	set.AddLanguage("fi")
+	set.AddLanguage("sv")
+	set.AddLanguage("fi")
+	set.AddLanguage("en")
+
+	assert.Equal(t, []string{"fi", "sv", "en"}, set.Languages, "")
+	assert.True(t, set.HasLanguage("sv"), "")
+	assert.False(t, set.HasLanguage("de"), "")
+}
+
+func TestOrderLanguages(t *testing.T) {
+	ass := func(expected []string, order []string) {
+		set := model.NewTranslationSet()
+		for _, language := range []string{"fi", "sv", "en", "de"} {
+			set.AddLanguage(language)
+		}
+		set.OrderLanguages(order)
+		assert.Equal(t, expected, set.Languages, "")
+	}
+
+	ass([]string{"fi", "sv", "en", "de"}, []string{})
+	ass([]string{"en", "fi", "sv", "de"}, []string{"en"})
+	ass([]string{"de", "en", "fi", "sv"}, []string{"de", "en"})
+
+	// Unknown and duplicate languages in the order are ignored
+	ass([]string{"en", "fi", "sv", "de"}, []string{"jp", "en", "en"})
+}
+
+func TestValueArguments(t *testing.T) {
+	seg := model.NewFormatSpecifierSegment
+	ass := func(expected []model.FormatSpecifierSegment, segments []model.Segment) {
+		assert.Equal(t, expected, model.TranslationValue{Segments: segments}.Arguments(), "")
+	}
+
+	ass([]model.FormatSpecifierSegment{}, []model.Segment{model.NewTextSegment("Foo")})
+
+	// Implicit order; text segments don't count
+	ass([]model.FormatSpecifierSegment{seg(model.DataTypeString, -1, -1), seg(model.DataTypeInteger, -1, -1)},
+		[]model.Segment{model.NewTextSegment("a"), seg(model.DataTypeString, -1, -1), model.NewTextSegment("b"), seg(model.DataTypeInteger, -1, -1)})
+
+	// Explicit order
+	ass([]model.FormatSpecifierSegment{seg(model.DataTypeInteger, -1, 1), seg(model.DataTypeString, -1, 2)},
+		[]model.Segment{seg(model.DataTypeString, -1, 2), seg(model.DataTypeInteger, -1, 1)})
+
+	// Gaps and repeated arguments
+	ass([]model.FormatSpecifierSegment{seg(model.DataTypeNone, -1, -1), seg(model.DataTypeFloat, 2, 2)},
+		[]model.Segment{seg(model.DataTypeFloat, 2, 2), seg(model.DataTypeString, -1, 2)})
+}
+
+func TestMaxLengthForPlatform(t *testing.T) {
+	translation := model.Translation{
+		MaxLength:          20,
+		PlatformMaxLengths: map[model.TranslationPlatform]int{model.PlatformAndroid: 12},
+	}
+	assert.Equal(t, 12, translation.MaxLengthForPlatform(model.PlatformAndroid), "")
+	assert.Equal(t, 20, translation.MaxLengthForPlatform(model.PlatformApple), "")
+	assert.Equal(t, 0, model.Translation{}.MaxLengthForPlatform(model.PlatformApple), "No limit")
+}
+
+func TestArgumentNames(t *testing.T) {
+	specifier := model.NewFormatSpecifierSegment(model.DataTypeObject, -1, 3)
+	assert.Equal(t, "arg1", model.ArgumentName(0), "")
+	assert.Equal(t, "arg3", model.ArgumentName(specifier.ArgumentIndex(0)), "Explicit order index overrides actual position")
+}
@@ -2,7 +2,6 @@ package android
 
 import (
 	"fmt"
-	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -36,7 +35,7 @@ func SanitizedForString(text string) string {
 	return util.XMLEscaped(strings.Replace(text, "%", "%%", -1))
 }
 
-func stringFromSegments(segments []model.Segment) string {
+func StringFromSegments(segments []model.Segment) string {
 	ret := ""
 	for _, segment := range segments {
 		switch segment.(type) {
@@ -76,28 +75,28 @@ func GetStringsFileContents(set model.TranslationSet, language string) string {
 				ret += fmt.Sprintf("    <!-- %s -->\n",
 					sanitizedForXMLComment(translation.Comment))
 			}
+			if maxLength := translation.MaxLengthForPlatform(model.PlatformAndroid); 0 < maxLength {
+				ret += fmt.Sprintf("    <!-- maxLength: %d -->\n", maxLength)
+			}
 			ret += fmt.Sprintf("    <string name=\"%s\">%s</string>\n",
 				util.XMLEscaped(translation.Key),
-				stringFromSegments(value.Segments))
+				StringFromSegments(value.Segments))
 		}
 	}
 	ret += "</resources>\n"
 	return ret
 }
 
-func WriteStringsFiles(set model.TranslationSet, outDirPath string) {
-	for language, _ := range set.Languages {
-		valuesDirPath := path.Join(outDirPath, "values-"+language)
-		os.MkdirAll(valuesDirPath, 0777)
-
-		f, err := os.Create(path.Join(valuesDirPath, "strings.xml"))
-		if err != nil {
-			panic(err)
-		}
-
-		_, err = f.WriteString(GetStringsFileContents(set, language))
-		if err != nil {
-			panic(err)
-		}
+// GetStringsFiles returns the contents of all the files that
+// WriteStringsFiles writes, keyed by path relative to the output directory.
+func GetStringsFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[path.Join("values-"+language, "strings.xml")] = GetStringsFileContents(set, language)
 	}
+	return ret
+}
+
+func WriteStringsFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetStringsFiles(set))
 }
@@ -1,6 +1,7 @@
 package android_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,8 +80,15 @@ func TestOverallXMLFileGeneration(t *testing.T) {
 
 func TestComprehensiveInput(t *testing.T) {
 	set := test.GetComprehensiveTestInputTranslationSet()
-	for language, _ := range set.Languages {
+	for _, language := range set.Languages {
 		output := android.GetStringsFileContents(set, language)
 		assert.True(t, util.XMLIsValid(output), language)
 	}
 }
+
+func TestMaxLengthComment(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	output := android.GetStringsFileContents(set, "en")
+	assert.True(t, strings.Contains(output, "    <!-- Foo bar! -->\n    <!-- maxLength: 150 -->\n    <string name=\"Toka\">"), "The Android-specific maximum length is used")
+	assert.Equal(t, 1, strings.Count(output, "maxLength"), "")
+}
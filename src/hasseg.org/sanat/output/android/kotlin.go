@@ -0,0 +1,195 @@
+package android
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// The generated Kotlin accessors are extension functions on `Resources`
+// (and `Context`) that call `getString()` with the resource IDs of the
+// strings.xml files written by the "android" format. The argument types
+// come from the first language of the set, so changing the type of a
+// format specifier there breaks the build instead of causing an
+// IllegalFormatConversionException at runtime.
+//
+// Since translations don't have plural forms, `getQuantityString()` is
+// never used.
+
+// KotlinPackageName is the package of the generated Kotlin file, and of
+// the `R` class it refers to. It has to be set (see CheckKotlinOptions.)
+var KotlinPackageName = ""
+
+// CheckKotlinOptions returns an error if the Kotlin file can't be
+// generated with the current options: the file refers to the `R` class
+// of the app, so it doesn't compile without a package.
+func CheckKotlinOptions() error {
+	if len(KotlinPackageName) == 0 {
+		return errors.New("The kotlin output format needs the package of the app's R class (use --package)")
+	}
+	return nil
+}
+
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true,
+	"else": true, "false": true, "for": true, "fun": true, "if": true,
+	"in": true, "interface": true, "is": true, "null": true, "object": true,
+	"package": true, "return": true, "super": true, "this": true,
+	"throw": true, "true": true, "try": true, "typealias": true,
+	"typeof": true, "val": true, "var": true, "when": true, "while": true,
+}
+
+// KotlinIdentifier turns s into a lower camel-cased Kotlin identifier,
+// escaping it with backticks if it is a keyword.
+func KotlinIdentifier(s string) string {
+	ret := util.CamelCaseIdentifier(s, false)
+	if kotlinKeywords[ret] {
+		return "`" + ret + "`"
+	}
+	return ret
+}
+
+var invalidResourceNameCharsRegexp = regexp.MustCompile("[^A-Za-z0-9_]")
+
+// ResourceFieldName returns the name of the field in the `R.string`
+// class for the string resource with the given name. aapt replaces
+// dots with underscores.
+func ResourceFieldName(key string) string {
+	return invalidResourceNameCharsRegexp.ReplaceAllString(key, "_")
+}
+
+// KotlinTypeForDataType returns the Kotlin type of the arguments of
+// format specifiers with the given data type.
+func KotlinTypeForDataType(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "String"
+	case model.DataTypeInteger:
+		return "Int"
+	case model.DataTypeFloat:
+		return "Double"
+	}
+	return "Any"
+}
+
+func kotlinDocComment(paragraphs []string) string {
+	ret := "/**\n"
+	for i, paragraph := range paragraphs {
+		if 0 < i {
+			ret += " *\n"
+		}
+		for _, line := range strings.Split(strings.Replace(paragraph, "*/", "* /", -1), "\n") {
+			ret += strings.TrimRight(" * "+line, " ") + "\n"
+		}
+	}
+	return ret + " */\n"
+}
+
+func docTextFromSegments(segments []model.Segment) string {
+	ret := ""
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += segment.(model.TextSegment).Text
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment))
+		}
+	}
+	return ret
+}
+
+func kotlinAccessors(translation model.Translation, sourceLanguage string, name string) string {
+	docParagraphs := make([]string, 0)
+	value := translation.ValueForLanguage(sourceLanguage)
+	var arguments []model.FormatSpecifierSegment
+	if value != nil {
+		docParagraphs = append(docParagraphs, docTextFromSegments(value.Segments))
+		arguments = value.Arguments()
+	}
+	if 0 < len(translation.Comment) {
+		docParagraphs = append(docParagraphs, translation.Comment)
+	}
+
+	parameters := make([]string, 0, len(arguments))
+	argumentNames := make([]string, 0, len(arguments))
+	for index, argument := range arguments {
+		argumentName := model.ArgumentName(index)
+		parameters = append(parameters, argumentName+": "+KotlinTypeForDataType(argument.DataType))
+		argumentNames = append(argumentNames, argumentName)
+	}
+	parameterList := strings.Join(parameters, ", ")
+	argumentList := strings.Join(argumentNames, ", ")
+
+	getStringArguments := "R.string." + ResourceFieldName(translation.Key)
+	if 0 < len(argumentNames) {
+		getStringArguments += ", " + argumentList
+	}
+
+	ret := ""
+	if 0 < len(docParagraphs) {
+		ret += kotlinDocComment(docParagraphs)
+	}
+	ret += "fun Resources." + name + "(" + parameterList + "): String = getString(" + getStringArguments + ")\n"
+	if 0 < len(docParagraphs) {
+		ret += kotlinDocComment(docParagraphs)
+	}
+	ret += "fun Context." + name + "(" + parameterList + "): String = resources." + name + "(" + argumentList + ")\n"
+	return ret
+}
+
+// GetKotlinFileContents returns Kotlin source code with typed accessors
+// for the translations.
+func GetKotlinFileContents(set model.TranslationSet) string {
+	sourceLanguage := ""
+	if 0 < len(set.Languages) {
+		sourceLanguage = set.Languages[0]
+	}
+
+	ret := "// Generated by Sanat\n\n"
+	if 0 < len(KotlinPackageName) {
+		ret += "package " + KotlinPackageName + "\n\n"
+	}
+	ret += "import android.content.Context\nimport android.content.res.Resources\n"
+	if 0 < len(KotlinPackageName) {
+		ret += "import " + KotlinPackageName + ".R\n"
+	}
+
+	namesUsed := make(map[string]string)
+	for _, section := range set.Sections {
+		sectionHeadingPrinted := false
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformAndroid) {
+				continue
+			}
+
+			name := KotlinIdentifier(translation.Key)
+			if originalKey, exists := namesUsed[name]; exists {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"': its Kotlin name '"+name+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			namesUsed[name] = translation.Key
+
+			if !sectionHeadingPrinted && 0 < len(section.Name) {
+				ret += "\n// ********** " + strings.Replace(section.Name, "\n", " ", -1) + " **********\n"
+				sectionHeadingPrinted = true
+			}
+			ret += "\n" + kotlinAccessors(translation, sourceLanguage, name)
+		}
+	}
+	return ret
+}
+
+// GetKotlinFiles returns the contents of the file that WriteKotlinFile
+// writes, keyed by path relative to the output directory.
+func GetKotlinFiles(set model.TranslationSet) map[string]string {
+	return map[string]string{"Strings.kt": GetKotlinFileContents(set)}
+}
+
+func WriteKotlinFile(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetKotlinFiles(set))
+}
@@ -0,0 +1,92 @@
+package android_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/android"
+	"hasseg.org/sanat/test"
+)
+
+func TestKotlinNames(t *testing.T) {
+	assert.Equal(t, "loginViewTitle", android.KotlinIdentifier("LoginView.Title"), "")
+	assert.Equal(t, "`object`", android.KotlinIdentifier("Object"), "")
+	assert.Equal(t, "LoginView_Title", android.ResourceFieldName("LoginView.Title"), "")
+}
+
+func TestKotlinFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("Login")
+	title := section.AddTranslation("LoginView.Title")
+	title.Comment = "Toolbar title */"
+	title.AddValue("en", []model.Segment{model.NewTextSegment("Log in")})
+	greeting := section.AddTranslation("LoginView.Greeting")
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+		model.NewTextSegment(" for "),
+		model.NewFormatSpecifierSegment(model.DataTypeInteger, -1, 3),
+		model.NewTextSegment(" "),
+		model.NewFormatSpecifierSegment(model.DataTypeObject, -1, 4),
+	})
+	section.AddTranslation("LoginView title").AddValue("en", []model.Segment{model.NewTextSegment("Collides")})
+	apple := section.AddTranslation("AppleOnly")
+	apple.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	apple.AddValue("en", []model.Segment{model.NewTextSegment("Apple")})
+	ts.AddLanguage("en")
+
+	android.KotlinPackageName = "com.example.app"
+	defer func() { android.KotlinPackageName = "" }()
+
+	assert.Equal(t, `// Generated by Sanat
+
+package com.example.app
+
+import android.content.Context
+import android.content.res.Resources
+import com.example.app.R
+
+// ********** Login **********
+
+/**
+ * Log in
+ *
+ * Toolbar title * /
+ */
+fun Resources.loginViewTitle(): String = getString(R.string.LoginView_Title)
+/**
+ * Log in
+ *
+ * Toolbar title * /
+ */
+fun Context.loginViewTitle(): String = resources.loginViewTitle()
+
+/**
+ * Hi %2$s, you owe %1$.2f for %3$d %4$s
+ */
+fun Resources.loginViewGreeting(arg1: Double, arg2: String, arg3: Int, arg4: Any): String = getString(R.string.LoginView_Greeting, arg1, arg2, arg3, arg4)
+/**
+ * Hi %2$s, you owe %1$.2f for %3$d %4$s
+ */
+fun Context.loginViewGreeting(arg1: Double, arg2: String, arg3: Int, arg4: Any): String = resources.loginViewGreeting(arg1, arg2, arg3, arg4)
+`, android.GetKotlinFileContents(ts), "")
+}
+
+func TestKotlinComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	contents := android.GetKotlinFiles(set)["Strings.kt"]
+	assert.True(t, strings.HasPrefix(contents, "// Generated by Sanat\n"), "")
+}
+
+func TestKotlinOptions(t *testing.T) {
+	assert.NotNil(t, android.CheckKotlinOptions(), "The package is required")
+
+	android.KotlinPackageName = "com.example.app"
+	defer func() { android.KotlinPackageName = "" }()
+	assert.Nil(t, android.CheckKotlinOptions(), "")
+}
@@ -2,12 +2,12 @@ package apple
 
 import (
 	"fmt"
-	"os"
 	"path"
 	"strconv"
 	"strings"
 
 	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
 )
 
 func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment) string {
@@ -88,19 +88,16 @@ func GetStringsFileContents(set model.TranslationSet, language string) string {
 	return ret
 }
 
-func WriteStringsFiles(set model.TranslationSet, outDirPath string) {
-	for language, _ := range set.Languages {
-		lprojPath := path.Join(outDirPath, language+".lproj")
-		os.MkdirAll(lprojPath, 0777)
-
-		f, err := os.Create(path.Join(lprojPath, "Localizable.strings"))
-		if err != nil {
-			panic(err)
-		}
-
-		_, err = f.WriteString(GetStringsFileContents(set, language))
-		if err != nil {
-			panic(err)
-		}
+// GetStringsFiles returns the contents of all the files that
+// WriteStringsFiles writes, keyed by path relative to the output directory.
+func GetStringsFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[path.Join(language+".lproj", "Localizable.strings")] = GetStringsFileContents(set, language)
 	}
+	return ret
+}
+
+func WriteStringsFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetStringsFiles(set))
 }
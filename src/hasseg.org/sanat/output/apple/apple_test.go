@@ -70,7 +70,7 @@ func isValidPlist(plistString string) bool {
 
 func TestComprehensiveInput(t *testing.T) {
 	set := test.GetComprehensiveTestInputTranslationSet()
-	for language, _ := range set.Languages {
+	for _, language := range set.Languages {
 		output := apple.GetStringsFileContents(set, language)
 		assert.True(t, isValidPlist(output), language)
 	}
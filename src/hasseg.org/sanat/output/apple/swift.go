@@ -0,0 +1,220 @@
+package apple
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// The generated Swift accessors look up the strings from the default
+// table (i.e. the Localizable.strings files written by the "apple"
+// format) with NSLocalizedString(). Each named section becomes a nested
+// enum in the `Strings` enum, and each translation a static property (or
+// a static function, if it has format specifiers.) The argument types
+// and documentation come from the first language of the set.
+
+const SwiftRootTypeName = "Strings"
+
+var swiftKeywords = map[string]bool{
+	"associatedtype": true, "class": true, "deinit": true, "enum": true,
+	"extension": true, "fileprivate": true, "func": true, "import": true,
+	"init": true, "inout": true, "internal": true, "let": true, "open": true,
+	"operator": true, "private": true, "protocol": true, "public": true,
+	"rethrows": true, "static": true, "struct": true, "subscript": true,
+	"typealias": true, "var": true, "break": true, "case": true,
+	"continue": true, "default": true, "defer": true, "do": true,
+	"else": true, "fallthrough": true, "for": true, "guard": true, "if": true,
+	"in": true, "repeat": true, "return": true, "switch": true,
+	"where": true, "while": true, "as": true, "any": true, "catch": true,
+	"false": true, "is": true, "nil": true, "super": true, "self": true,
+	"Self": true, "throw": true, "throws": true, "true": true, "try": true,
+	"Type": true,
+}
+
+// SwiftIdentifier turns s into a camel-cased Swift identifier, escaping
+// it with backticks if it is a keyword.
+func SwiftIdentifier(s string, upperFirst bool) string {
+	ret := util.CamelCaseIdentifier(s, upperFirst)
+	if swiftKeywords[ret] {
+		return "`" + ret + "`"
+	}
+	return ret
+}
+
+// SwiftTypeForDataType returns the Swift type of the arguments of
+// format specifiers with the given data type.
+func SwiftTypeForDataType(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "String"
+	case model.DataTypeInteger:
+		return "Int"
+	case model.DataTypeFloat:
+		return "Double"
+	}
+	return "CVarArg"
+}
+
+// SwiftStringLiteral returns the text as a Swift string literal.
+func SwiftStringLiteral(text string) string {
+	ret := "\""
+	for _, c := range text {
+		switch c {
+		case '\\':
+			ret += "\\\\"
+		case '"':
+			ret += "\\\""
+		case '\n':
+			ret += "\\n"
+		case '\r':
+			ret += "\\r"
+		case '\t':
+			ret += "\\t"
+		case 0:
+			ret += "\\0"
+		default:
+			if c < 0x20 || c == 0x7f {
+				ret += fmt.Sprintf("\\u{%x}", c)
+			} else {
+				ret += string(c)
+			}
+		}
+	}
+	return ret + "\""
+}
+
+func swiftDocComment(text string, indent string) string {
+	ret := ""
+	for _, line := range strings.Split(text, "\n") {
+		ret += strings.TrimRight(indent+"/// "+line, " ") + "\n"
+	}
+	return ret
+}
+
+func docTextFromSegments(segments []model.Segment) string {
+	ret := ""
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += segment.(model.TextSegment).Text
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment))
+		}
+	}
+	return ret
+}
+
+func swiftAccessor(translation model.Translation, sourceLanguage string, name string, indent string) string {
+	ret := ""
+	value := translation.ValueForLanguage(sourceLanguage)
+	var arguments []model.FormatSpecifierSegment
+	if value != nil {
+		ret += swiftDocComment(docTextFromSegments(value.Segments), indent)
+		arguments = value.Arguments()
+	}
+	if 0 < len(translation.Comment) {
+		if value != nil {
+			ret += indent + "///\n"
+		}
+		ret += swiftDocComment(translation.Comment, indent)
+	}
+
+	lookup := "NSLocalizedString(" + SwiftStringLiteral(translation.Key) + ", comment: " + SwiftStringLiteral(translation.Comment) + ")"
+	if len(arguments) == 0 {
+		ret += indent + "static var " + name + ": String {\n"
+		ret += indent + "    return " + lookup + "\n"
+		ret += indent + "}\n"
+		return ret
+	}
+
+	parameters := make([]string, 0, len(arguments))
+	argumentNames := make([]string, 0, len(arguments))
+	for index, argument := range arguments {
+		argumentName := model.ArgumentName(index)
+		parameters = append(parameters, "_ "+argumentName+": "+SwiftTypeForDataType(argument.DataType))
+		argumentNames = append(argumentNames, argumentName)
+	}
+	ret += indent + "static func " + name + "(" + strings.Join(parameters, ", ") + ") -> String {\n"
+	ret += indent + "    return String(format: " + lookup + ", " + strings.Join(argumentNames, ", ") + ")\n"
+	ret += indent + "}\n"
+	return ret
+}
+
+type swiftEnum struct {
+	name         string
+	sectionName  string
+	translations []model.Translation
+}
+
+// GetSwiftFileContents returns Swift source code with typed accessors for
+// the translations.
+func GetSwiftFileContents(set model.TranslationSet) string {
+	sourceLanguage := ""
+	if 0 < len(set.Languages) {
+		sourceLanguage = set.Languages[0]
+	}
+
+	// Sections with the same (sanitized) name end up in the same enum;
+	// translations in unnamed sections go directly into the root enum
+	rootTranslations := make([]model.Translation, 0)
+	enums := make([]*swiftEnum, 0)
+	enumsByName := make(map[string]*swiftEnum)
+	for _, section := range set.Sections {
+		if len(section.Name) == 0 {
+			rootTranslations = append(rootTranslations, section.Translations...)
+			continue
+		}
+		name := SwiftIdentifier(section.Name, true)
+		enum, exists := enumsByName[name]
+		if !exists {
+			enum = &swiftEnum{name: name, sectionName: section.Name, translations: make([]model.Translation, 0)}
+			enums = append(enums, enum)
+			enumsByName[name] = enum
+		}
+		enum.translations = append(enum.translations, section.Translations...)
+	}
+
+	ret := "// Generated by Sanat\n\nimport Foundation\n\n"
+	ret += "enum " + SwiftRootTypeName + " {\n"
+
+	writeAccessors := func(translations []model.Translation, indent string, namesUsed map[string]string) {
+		for _, translation := range translations {
+			if !translation.IsForPlatform(model.PlatformApple) {
+				continue
+			}
+			name := SwiftIdentifier(translation.Key, false)
+			if originalKey, exists := namesUsed[name]; exists {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"': its Swift name '"+name+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			namesUsed[name] = translation.Key
+			ret += "\n" + swiftAccessor(translation, sourceLanguage, name, indent)
+		}
+	}
+
+	rootNamesUsed := make(map[string]string)
+	for _, enum := range enums {
+		rootNamesUsed[enum.name] = enum.sectionName
+	}
+	writeAccessors(rootTranslations, "    ", rootNamesUsed)
+	for _, enum := range enums {
+		ret += "\n    enum " + enum.name + " {\n"
+		writeAccessors(enum.translations, "        ", make(map[string]string))
+		ret += "    }\n"
+	}
+	ret += "}\n"
+	return ret
+}
+
+// GetSwiftFiles returns the contents of the file that WriteSwiftFile
+// writes, keyed by path relative to the output directory.
+func GetSwiftFiles(set model.TranslationSet) map[string]string {
+	return map[string]string{SwiftRootTypeName + ".swift": GetSwiftFileContents(set)}
+}
+
+func WriteSwiftFile(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetSwiftFiles(set))
+}
@@ -0,0 +1,85 @@
+package apple_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/apple"
+	"hasseg.org/sanat/test"
+)
+
+func TestSwiftIdentifier(t *testing.T) {
+	assert.Equal(t, "loginViewTitle", apple.SwiftIdentifier("LoginView.Title", false), "")
+	assert.Equal(t, "LoginView", apple.SwiftIdentifier("Login view", true), "")
+	assert.Equal(t, "`default`", apple.SwiftIdentifier("default", false), "")
+	assert.Equal(t, "key1", apple.SwiftIdentifier("1", false), "")
+}
+
+func TestSwiftStringLiteral(t *testing.T) {
+	assert.Equal(t, `""`, apple.SwiftStringLiteral(""), "")
+	assert.Equal(t, `"Say \"hi\" \\ \n\t\u{7}"`, apple.SwiftStringLiteral("Say \"hi\" \\ \n\t\a"), "")
+}
+
+func TestSwiftFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	root := ts.AddSection("")
+	root.AddTranslation("OK").AddValue("en", []model.Segment{model.NewTextSegment("OK")})
+	login := ts.AddSection("Login view")
+	title := login.AddTranslation("LoginView.Title")
+	title.Comment = "Navigation bar title"
+	title.AddValue("en", []model.Segment{model.NewTextSegment("Log in")})
+	greeting := login.AddTranslation("LoginView.Greeting")
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+		model.NewTextSegment(" for "),
+		model.NewFormatSpecifierSegment(model.DataTypeInteger, -1, 3),
+		model.NewTextSegment(" "),
+		model.NewFormatSpecifierSegment(model.DataTypeObject, -1, 4),
+	})
+	login.AddTranslation("LoginView title").AddValue("en", []model.Segment{model.NewTextSegment("Collides")})
+	android := login.AddTranslation("AndroidOnly")
+	android.Platforms = []model.TranslationPlatform{model.PlatformAndroid}
+	android.AddValue("en", []model.Segment{model.NewTextSegment("Droid")})
+	ts.AddLanguage("en")
+
+	assert.Equal(t, `// Generated by Sanat
+
+import Foundation
+
+enum Strings {
+
+    /// OK
+    static var oK: String {
+        return NSLocalizedString("OK", comment: "")
+    }
+
+    enum LoginView {
+
+        /// Log in
+        ///
+        /// Navigation bar title
+        static var loginViewTitle: String {
+            return NSLocalizedString("LoginView.Title", comment: "Navigation bar title")
+        }
+
+        /// Hi %2$@, you owe %1$.2f for %3$d %4$@
+        static func loginViewGreeting(_ arg1: Double, _ arg2: String, _ arg3: Int, _ arg4: CVarArg) -> String {
+            return String(format: NSLocalizedString("LoginView.Greeting", comment: ""), arg1, arg2, arg3, arg4)
+        }
+    }
+}
+`, apple.GetSwiftFileContents(ts), "")
+}
+
+func TestSwiftComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	contents := apple.GetSwiftFiles(set)["Strings.swift"]
+	assert.True(t, strings.HasPrefix(contents, "// Generated by Sanat\n"), "")
+	assert.Equal(t, strings.Count(contents, "{"), strings.Count(contents, "}"), "")
+}
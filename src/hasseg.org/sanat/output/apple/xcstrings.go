@@ -0,0 +1,117 @@
+package apple
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// String Catalog (.xcstrings) files are JSON documents containing the
+// values for all languages. Xcode sorts the keys of the objects, which is
+// what encoding/json does with maps as well.
+
+type StringCatalog struct {
+	SourceLanguage string                         `json:"sourceLanguage"`
+	Strings        map[string]StringCatalogString `json:"strings"`
+	Version        string                         `json:"version"`
+}
+
+type StringCatalogString struct {
+	Comment         string                               `json:"comment,omitempty"`
+	ExtractionState string                               `json:"extractionState"`
+	Localizations   map[string]StringCatalogLocalization `json:"localizations"`
+}
+
+type StringCatalogLocalization struct {
+	StringUnit StringCatalogStringUnit `json:"stringUnit"`
+}
+
+type StringCatalogStringUnit struct {
+	State string `json:"state"`
+	Value string `json:"value"`
+}
+
+func SanitizedForStringCatalogValue(text string) string {
+	return strings.Replace(text, "%", "%%", -1)
+}
+
+func stringCatalogValueFromSegments(segments []model.Segment) string {
+	ret := ""
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += SanitizedForStringCatalogValue(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment))
+		}
+	}
+	return ret
+}
+
+// StringCatalogForTranslationSet returns a String Catalog containing all
+// the languages in the set. The first language of the set is used as
+// the source language. Values that are empty are marked as "new" (i.e.
+// not yet translated) and languages that have no value at all are left
+// out so that they fall back to the source language. Translations whose
+// key is the same as that of a translation in an earlier section are
+// skipped with a warning.
+func StringCatalogForTranslationSet(set model.TranslationSet) StringCatalog {
+	ret := StringCatalog{
+		Strings: make(map[string]StringCatalogString),
+		Version: "1.0",
+	}
+	if 0 < len(set.Languages) {
+		ret.SourceLanguage = set.Languages[0]
+	}
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformApple) {
+				continue
+			}
+			if _, exists := ret.Strings[translation.Key]; exists {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"' in section '"+section.Name+"': its key is the same as that of an earlier translation")
+				continue
+			}
+
+			catalogString := StringCatalogString{
+				Comment:         translation.Comment,
+				ExtractionState: "manual",
+				Localizations:   make(map[string]StringCatalogLocalization),
+			}
+			for _, language := range set.Languages {
+				value := translation.ValueForLanguage(language)
+				if value == nil {
+					continue
+				}
+				stringUnit := StringCatalogStringUnit{
+					State: "translated",
+					Value: stringCatalogValueFromSegments(value.Segments),
+				}
+				if len(stringUnit.Value) == 0 {
+					stringUnit.State = "new"
+				}
+				catalogString.Localizations[language] = StringCatalogLocalization{StringUnit: stringUnit}
+			}
+			ret.Strings[translation.Key] = catalogString
+		}
+	}
+	return ret
+}
+
+func GetStringCatalogFileContents(set model.TranslationSet) string {
+	return util.IndentedJSON(StringCatalogForTranslationSet(set))
+}
+
+// GetStringCatalogFiles returns the contents of the file that
+// WriteStringCatalogFile writes, keyed by path relative to the output
+// directory.
+func GetStringCatalogFiles(set model.TranslationSet) map[string]string {
+	return map[string]string{"Localizable.xcstrings": GetStringCatalogFileContents(set)}
+}
+
+func WriteStringCatalogFile(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetStringCatalogFiles(set))
+}
@@ -0,0 +1,89 @@
+package apple_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/apple"
+	"hasseg.org/sanat/test"
+)
+
+func TestTextSanitizedForStringCatalogValue(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, apple.SanitizedForStringCatalogValue(input), input)
+	}
+
+	ass("", "")
+	ass("Foo", "Foo")
+	ass("Per %% cent", "Per % cent")
+	ass("Foo \"bar\"", "Foo \"bar\"") // Quotes are escaped by JSON instead
+}
+
+func TestStringCatalogFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	greeting := section.AddTranslation("Greeting")
+	greeting.Comment = "Shown at launch"
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(", 100%"),
+	})
+	greeting.AddValue("fi", []model.Segment{})
+	androidOnly := section.AddTranslation("AndroidOnly")
+	androidOnly.Platforms = []model.TranslationPlatform{model.PlatformAndroid}
+	androidOnly.AddValue("en", []model.Segment{model.NewTextSegment("Droid")})
+	section.AddTranslation("EnglishOnly").AddValue("en", []model.Segment{model.NewTextSegment("Hello")})
+	duplicate := ts.AddSection("Other").AddTranslation("Greeting")
+	duplicate.Comment = "Duplicate"
+	duplicate.AddValue("en", []model.Segment{model.NewTextSegment("Duplicate")})
+	ts.AddLanguage("en")
+	ts.AddLanguage("fi")
+
+	assert.Equal(t, `{
+  "sourceLanguage": "en",
+  "strings": {
+    "EnglishOnly": {
+      "extractionState": "manual",
+      "localizations": {
+        "en": {
+          "stringUnit": {
+            "state": "translated",
+            "value": "Hello"
+          }
+        }
+      }
+    },
+    "Greeting": {
+      "comment": "Shown at launch",
+      "extractionState": "manual",
+      "localizations": {
+        "en": {
+          "stringUnit": {
+            "state": "translated",
+            "value": "Hi %@, 100%%"
+          }
+        },
+        "fi": {
+          "stringUnit": {
+            "state": "new",
+            "value": ""
+          }
+        }
+      }
+    }
+  },
+  "version": "1.0"
+}
+`, apple.GetStringCatalogFileContents(ts), "")
+}
+
+func TestStringCatalogComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	var catalog apple.StringCatalog
+	assert.Nil(t, json.Unmarshal([]byte(apple.GetStringCatalogFileContents(set)), &catalog), "")
+	assert.Equal(t, set.Languages[0], catalog.SourceLanguage, "")
+}
@@ -0,0 +1,158 @@
+package arb
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/icu"
+	"hasseg.org/sanat/util"
+)
+
+// FormatSpecifierStringForFormatSpecifier returns the placeholder
+// reference for the format specifier. The formatting (e.g. the number of
+// decimals) is described in the placeholder metadata instead of the
+// message itself.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	return "{" + model.ArgumentName(segment.ArgumentIndex(position)) + "}"
+}
+
+// PlaceholderForFormatSpecifier returns the `placeholders` metadata
+// entry describing the argument of the format specifier.
+func PlaceholderForFormatSpecifier(segment model.FormatSpecifierSegment) *util.OrderedMap {
+	ret := util.NewOrderedMap()
+	switch segment.DataType {
+	case model.DataTypeString:
+		ret.Set("type", "String")
+	case model.DataTypeInteger:
+		ret.Set("type", "int")
+	case model.DataTypeFloat:
+		ret.Set("type", "double")
+		if 0 <= segment.NumberOfDecimals {
+			ret.Set("format", "decimalPatternDigits")
+			optionalParameters := util.NewOrderedMap()
+			optionalParameters.Set("decimalDigits", segment.NumberOfDecimals)
+			ret.Set("optionalParameters", optionalParameters)
+		}
+	default:
+		ret.Set("type", "Object")
+	}
+	return ret
+}
+
+// SanitizedForKey turns the translation key into a valid Dart method
+// name, which is what Flutter's code generator requires ARB keys to be.
+func SanitizedForKey(key string) string {
+	return util.CamelCaseIdentifier(key, false)
+}
+
+// LocaleForLanguage turns a BCP 47 language identifier into the
+// underscore-separated form used in ARB file names and `@@locale`.
+func LocaleForLanguage(language string) string {
+	return strings.Replace(language, "-", "_", -1)
+}
+
+func stringFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += icu.SanitizedForMessageText(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+// arbKeys returns the ARB keys of the translations in the set, indexed
+// like set.Sections[i].Translations[j], along with warnings about the
+// translations that are skipped (and get an empty key) because their ARB
+// key is the same as that of an earlier translation. The keys don't
+// depend on the language, so that the same key refers to the same
+// translation in all of the ARB files.
+func arbKeys(set model.TranslationSet) ([][]string, []string) {
+	keys := make([][]string, len(set.Sections))
+	warnings := make([]string, 0)
+	keysBySanitizedKey := make(map[string]string)
+	for sectionIndex, section := range set.Sections {
+		keys[sectionIndex] = make([]string, len(section.Translations))
+		for translationIndex, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			key := SanitizedForKey(translation.Key)
+			if originalKey, exists := keysBySanitizedKey[key]; exists {
+				warnings = append(warnings, "Skipping translation '"+translation.Key+"': its ARB key '"+key+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			keysBySanitizedKey[key] = translation.Key
+			keys[sectionIndex][translationIndex] = key
+		}
+	}
+	return keys, warnings
+}
+
+func GetARBFileContents(set model.TranslationSet, language string) string {
+	keys, _ := arbKeys(set)
+	return arbFileContents(set, language, keys)
+}
+
+func arbFileContents(set model.TranslationSet, language string, keys [][]string) string {
+	arb := util.NewOrderedMap()
+	arb.Set("@@locale", LocaleForLanguage(language))
+
+	for sectionIndex, section := range set.Sections {
+		for translationIndex, translation := range section.Translations {
+			key := keys[sectionIndex][translationIndex]
+			if len(key) == 0 {
+				continue
+			}
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+
+			arb.Set(key, stringFromSegments(value.Segments))
+
+			metadata := util.NewOrderedMap()
+			if 0 < len(translation.Comment) {
+				metadata.Set("description", translation.Comment)
+			}
+			placeholders := util.NewOrderedMap()
+			for index, argument := range value.Arguments() {
+				if argument.DataType != model.DataTypeNone {
+					placeholders.Set(model.ArgumentName(index), PlaceholderForFormatSpecifier(argument))
+				}
+			}
+			if 0 < len(placeholders.Keys) {
+				metadata.Set("placeholders", placeholders)
+			}
+			if 0 < len(metadata.Keys) {
+				arb.Set("@"+key, metadata)
+			}
+		}
+	}
+	return util.IndentedJSON(arb)
+}
+
+// GetARBFiles returns the contents of all the files that WriteARBFiles
+// writes, keyed by path relative to the output directory.
+func GetARBFiles(set model.TranslationSet) map[string]string {
+	keys, warnings := arbKeys(set)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "WARNING: "+warning)
+	}
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret["app_"+LocaleForLanguage(language)+".arb"] = arbFileContents(set, language, keys)
+	}
+	return ret
+}
+
+func WriteARBFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetARBFiles(set))
+}
@@ -0,0 +1,100 @@
+package arb_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/arb"
+	"hasseg.org/sanat/test"
+)
+
+func TestARBFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return arb.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "{arg1}", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "{arg1}", val(model.DataTypeFloat, 0, 2, -1), "Decimal count goes into the metadata")
+	assert.Equal(t, "{arg3}", val(model.DataTypeString, 2, -1, -1), "")
+	assert.Equal(t, "{arg12}", val(model.DataTypeString, 2, -1, 12), "Explicit order index overrides actual position")
+}
+
+func TestARBPlaceholderForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType, numDecimals int) string {
+		b, _ := json.Marshal(arb.PlaceholderForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, -1)))
+		return string(b)
+	}
+
+	assert.Equal(t, `{"type":"Object"}`, val(model.DataTypeObject, -1), "")
+	assert.Equal(t, `{"type":"String"}`, val(model.DataTypeString, -1), "")
+	assert.Equal(t, `{"type":"int"}`, val(model.DataTypeInteger, -1), "")
+	assert.Equal(t, `{"type":"double"}`, val(model.DataTypeFloat, -1), "")
+	assert.Equal(t, `{"type":"double","format":"decimalPatternDigits","optionalParameters":{"decimalDigits":2}}`, val(model.DataTypeFloat, 2), "")
+}
+
+func TestARBFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	translation := section.AddTranslation("Temperature.Label")
+	translation.Comment = "Shown on the main screen"
+	translation.AddValue("en-US", []model.Segment{
+		model.NewTextSegment("It's "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 1, -1),
+		model.NewTextSegment(" {°C} in "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+	})
+	section.AddTranslation("Plain").AddValue("en-US", []model.Segment{model.NewTextSegment("Plain")})
+	collides := section.AddTranslation("plain")
+	collides.AddValue("en-US", []model.Segment{model.NewTextSegment("Collides")})
+	collides.AddValue("fi", []model.Segment{model.NewTextSegment("Törmää")})
+	appleOnly := ts.AddSection("Platform specific").AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en-US", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en-US")
+	ts.AddLanguage("fi")
+
+	assert.Equal(t, `{
+  "@@locale": "en_US",
+  "temperatureLabel": "It''s {arg1} '{'°C'}' in {arg2}",
+  "@temperatureLabel": {
+    "description": "Shown on the main screen",
+    "placeholders": {
+      "arg1": {
+        "type": "double",
+        "format": "decimalPatternDigits",
+        "optionalParameters": {
+          "decimalDigits": 1
+        }
+      },
+      "arg2": {
+        "type": "String"
+      }
+    }
+  },
+  "plain": "Plain"
+}
+`, arb.GetARBFileContents(ts, "en-US"), "")
+	assert.Equal(t, `{
+  "@@locale": "fi"
+}
+`, arb.GetARBFileContents(ts, "fi"), "Keys that collide in any language are skipped in all of them")
+
+	_, exists := arb.GetARBFiles(ts)["app_en_US.arb"]
+	assert.True(t, exists, "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	files := arb.GetARBFiles(set)
+	assert.Equal(t, len(set.Languages), len(files), "")
+	for filePath, contents := range files {
+		var arbContents map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(contents), &arbContents), filePath)
+	}
+}
@@ -39,6 +39,10 @@ func StringForPlatform(platform model.TranslationPlatform) string {
 		return "Android"
 	case model.PlatformWindows:
 		return "Windows"
+	case model.PlatformJava:
+		return "Java"
+	case model.PlatformWeb:
+		return "Web"
 	}
 	return "??"
 }
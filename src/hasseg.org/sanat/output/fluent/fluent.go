@@ -0,0 +1,218 @@
+package fluent
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// FormatSpecifierStringForFormatSpecifier returns a placeable referring
+// to the argument of the format specifier as a variable. Floats with a
+// number of decimals are formatted with NUMBER(); otherwise the
+// formatting is left to Fluent.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	variable := "$" + model.ArgumentName(segment.ArgumentIndex(position))
+	if segment.DataType == model.DataTypeFloat && 0 <= segment.NumberOfDecimals {
+		decimals := strconv.Itoa(segment.NumberOfDecimals)
+		return "{ NUMBER(" + variable + ", minimumFractionDigits: " + decimals + ", maximumFractionDigits: " + decimals + ") }"
+	}
+	return "{ " + variable + " }"
+}
+
+func stringLiteralPlaceable(text string) string {
+	return "{\"" + strings.Replace(strings.Replace(text, "\\", "\\\\", -1), "\"", "\\\"", -1) + "\"}"
+}
+
+// SanitizedForText escapes the curly braces, which would otherwise start
+// (or end) a placeable.
+func SanitizedForText(text string) string {
+	ret := ""
+	for _, c := range text {
+		if c == '{' || c == '}' {
+			ret += stringLiteralPlaceable(string(c))
+		} else {
+			ret += string(c)
+		}
+	}
+	return ret
+}
+
+var invalidIdentifierCharsRegexp = regexp.MustCompile("[^A-Za-z0-9_-]")
+
+// SanitizedForIdentifier turns the translation key into a valid Fluent
+// message identifier by replacing disallowed characters (e.g. dots) with
+// dashes. Identifiers must also start with a letter.
+func SanitizedForIdentifier(key string) string {
+	ret := invalidIdentifierCharsRegexp.ReplaceAllString(key, "-")
+	if len(ret) == 0 || !(('a' <= ret[0] && ret[0] <= 'z') || ('A' <= ret[0] && ret[0] <= 'Z')) {
+		ret = "key-" + ret
+	}
+	return ret
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// escapedPatternLine keeps the leading and trailing whitespace of the
+// line, which Fluent would otherwise trim, by turning it into string
+// literals. If the line is indented (i.e. part of a multiline value), the
+// characters that would start a variant or an attribute are escaped too.
+func escapedPatternLine(line string, isIndented bool) string {
+	start := 0
+	for start < len(line) && isBlank(line[start]) {
+		start++
+	}
+	end := len(line)
+	for start < end && isBlank(line[end-1]) {
+		end--
+	}
+
+	ret := ""
+	if 0 < start {
+		ret += stringLiteralPlaceable(line[:start])
+	}
+	content := line[start:end]
+	if isIndented && 0 < len(content) && strings.ContainsRune("[*.", rune(content[0])) {
+		ret += stringLiteralPlaceable(content[:1])
+		content = content[1:]
+	}
+	ret += content
+	if end < len(line) {
+		ret += stringLiteralPlaceable(line[end:])
+	}
+	return ret
+}
+
+func patternFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += SanitizedForText(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+// messageFromSegments returns the ` = value` part of a message.
+// Multiline values are written on indented lines following the
+// identifier.
+func messageFromSegments(segments []model.Segment) string {
+	pattern := patternFromSegments(segments)
+	if len(pattern) == 0 {
+		return " = " + stringLiteralPlaceable("") + "\n"
+	}
+	if !strings.Contains(pattern, "\n") {
+		return " = " + escapedPatternLine(pattern, false) + "\n"
+	}
+	ret := " ="
+	for _, line := range strings.Split(pattern, "\n") {
+		if len(line) == 0 {
+			ret += "\n"
+		} else {
+			ret += "\n    " + escapedPatternLine(line, true)
+		}
+	}
+	return ret + "\n"
+}
+
+func commentLines(prefix string, text string) string {
+	ret := ""
+	for _, line := range strings.Split(text, "\n") {
+		ret += strings.TrimRight(prefix+" "+line, " ") + "\n"
+	}
+	return ret
+}
+
+// identifiersForSet returns the Fluent identifiers of the translations in the
+// set, indexed like set.Sections[i].Translations[j], along with warnings
+// about the translations that are skipped (and get an empty identifier)
+// because their identifier is the same as that of an earlier
+// translation. The identifiers don't depend on the language, so that the
+// same identifier refers to the same translation in all of the files.
+func identifiersForSet(set model.TranslationSet) ([][]string, []string) {
+	ret := make([][]string, len(set.Sections))
+	warnings := make([]string, 0)
+	identifiersUsed := make(map[string]string)
+	for sectionIndex, section := range set.Sections {
+		ret[sectionIndex] = make([]string, len(section.Translations))
+		for translationIndex, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			identifier := SanitizedForIdentifier(translation.Key)
+			if originalKey, exists := identifiersUsed[identifier]; exists {
+				warnings = append(warnings, "Skipping translation '"+translation.Key+"': its Fluent identifier '"+identifier+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			identifiersUsed[identifier] = translation.Key
+			ret[sectionIndex][translationIndex] = identifier
+		}
+	}
+	return ret, warnings
+}
+
+func GetFTLFileContents(set model.TranslationSet, language string) string {
+	identifiers, _ := identifiersForSet(set)
+	return ftlFileContents(set, language, identifiers)
+}
+
+func ftlFileContents(set model.TranslationSet, language string, identifiers [][]string) string {
+	ret := ""
+	for sectionIndex, section := range set.Sections {
+		sectionHeadingPrinted := false
+		for translationIndex, translation := range section.Translations {
+			identifier := identifiers[sectionIndex][translationIndex]
+			if len(identifier) == 0 {
+				continue
+			}
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+
+			if !sectionHeadingPrinted && 0 < len(section.Name) {
+				if 0 < len(ret) {
+					ret += "\n"
+				}
+				ret += commentLines("##", section.Name) + "\n"
+				sectionHeadingPrinted = true
+			}
+
+			if 0 < len(translation.Comment) {
+				ret += commentLines("#", translation.Comment)
+			}
+			ret += identifier + messageFromSegments(value.Segments)
+		}
+	}
+	return ret
+}
+
+// GetFTLFiles returns the contents of all the files that WriteFTLFiles
+// writes, keyed by path relative to the output directory.
+func GetFTLFiles(set model.TranslationSet) map[string]string {
+	identifiers, warnings := identifiersForSet(set)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "WARNING: "+warning)
+	}
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[path.Join(language, "main.ftl")] = ftlFileContents(set, language, identifiers)
+	}
+	return ret
+}
+
+func WriteFTLFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetFTLFiles(set))
+}
@@ -0,0 +1,91 @@
+package fluent_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/fluent"
+	"hasseg.org/sanat/test"
+)
+
+func TestFluentFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return fluent.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "{ $arg1 }", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "{ $arg2 }", val(model.DataTypeInteger, 1, -1, -1), "")
+	assert.Equal(t, "{ $arg1 }", val(model.DataTypeFloat, 0, -1, -1), "")
+	assert.Equal(t, "{ NUMBER($arg1, minimumFractionDigits: 2, maximumFractionDigits: 2) }", val(model.DataTypeFloat, 0, 2, -1), "")
+	assert.Equal(t, "{ $arg1 }", val(model.DataTypeString, 0, 2, -1), "Decimal count is only for floats")
+	assert.Equal(t, "{ $arg12 }", val(model.DataTypeString, 2, -1, 12), "Explicit order index overrides actual position")
+}
+
+func TestTextSanitizedForFluent(t *testing.T) {
+	assert.Equal(t, "Foo", fluent.SanitizedForText("Foo"), "")
+	assert.Equal(t, `a {"{"}b{"}"} c`, fluent.SanitizedForText("a {b} c"), "")
+
+	assert.Equal(t, "login-title", fluent.SanitizedForIdentifier("login.title"), "")
+	assert.Equal(t, "Login_View-Title", fluent.SanitizedForIdentifier("Login_View Title"), "")
+	assert.Equal(t, "key-123", fluent.SanitizedForIdentifier("123"), "")
+	assert.Equal(t, "key--x", fluent.SanitizedForIdentifier("-x"), "")
+}
+
+func TestFTLFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("Login")
+	greeting := section.AddTranslation("login.greeting")
+	greeting.Comment = "Shown after logging in"
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(", you owe {"),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, -1),
+		model.NewTextSegment("}"),
+	})
+	collides := section.AddTranslation("login greeting")
+	collides.AddValue("en", []model.Segment{model.NewTextSegment("Collides")})
+	collides.AddValue("fi", []model.Segment{model.NewTextSegment("Törmää")})
+	section.AddTranslation("padded").AddValue("en", []model.Segment{model.NewTextSegment("  both ends ")})
+	section.AddTranslation("multiline").AddValue("en", []model.Segment{model.NewTextSegment("First\n  second\n\n*third")})
+	section.AddTranslation("empty").AddValue("en", []model.Segment{})
+	other := ts.AddSection("Other")
+	other.AddTranslation("other").AddValue("en", []model.Segment{model.NewTextSegment("Other")})
+	appleOnly := ts.AddSection("Platform specific").AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en")
+	ts.AddLanguage("fi")
+
+	assert.Equal(t, `## Login
+
+# Shown after logging in
+login-greeting = Hi { $arg1 }, you owe {"{"}{ NUMBER($arg2, minimumFractionDigits: 2, maximumFractionDigits: 2) }{"}"}
+padded = {"  "}both ends{" "}
+multiline =
+    First
+    {"  "}second
+
+    {"*"}third
+empty = {""}
+
+## Other
+
+other = Other
+`, fluent.GetFTLFileContents(ts, "en"), "")
+	assert.Equal(t, "", fluent.GetFTLFileContents(ts, "fi"), "Identifiers that collide in any language are skipped in all of them")
+
+	_, exists := fluent.GetFTLFiles(ts)["en/main.ftl"]
+	assert.True(t, exists, "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	files := fluent.GetFTLFiles(set)
+	assert.Equal(t, len(set.Languages), len(files), "")
+}
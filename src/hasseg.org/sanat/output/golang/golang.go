@@ -0,0 +1,233 @@
+package golang
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// The generated Go package registers the translations into a
+// golang.org/x/text/message/catalog.Builder, keyed by the translation
+// keys, and has a typed accessor function for each translation that
+// formats it with a message.Printer. The first language of the set is
+// used as the fallback language, and the argument types and
+// documentation come from it too.
+//
+// Plural selectors are never used since translations don't have plural
+// forms.
+
+// PackageName is the name of the generated Go package (and of the file.)
+// It has to be a valid Go identifier (see CheckOptions.)
+var PackageName = "translations"
+
+// CheckOptions returns an error if the Go file can't be generated with
+// the current options.
+func CheckOptions() error {
+	if !token.IsIdentifier(PackageName) || PackageName == "_" {
+		return errors.New("Invalid Go package name '" + PackageName + "' (use --package to set a valid Go identifier, e.g. translations)")
+	}
+	return nil
+}
+
+// Names that are already taken in the generated package
+var reservedNames = map[string]bool{
+	"Catalog":    true,
+	"NewPrinter": true,
+}
+
+// The catalog would read `${` as the start of a substitution, so a
+// literal dollar sign followed by a brace is written as a substitution
+// of this variable (containing a dollar sign) instead.
+const dollarVarName = "dollar"
+
+// FormatSpecifierStringForFormatSpecifier returns the printf verb for
+// the format specifier, with an explicit argument index.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	ret := "%"
+	if segment.DataType == model.DataTypeFloat && 0 <= segment.NumberOfDecimals {
+		ret += "." + strconv.Itoa(segment.NumberOfDecimals)
+	}
+	ret += "[" + strconv.Itoa(segment.ArgumentIndex(position)+1) + "]"
+	switch segment.DataType {
+	case model.DataTypeString:
+		ret += "s"
+	case model.DataTypeInteger:
+		ret += "d"
+	case model.DataTypeFloat:
+		if 0 <= segment.NumberOfDecimals {
+			ret += "f"
+		} else {
+			ret += "v"
+		}
+	default:
+		ret += "v"
+	}
+	return ret
+}
+
+// SanitizedForMessage escapes percent signs and `${` sequences in the
+// text.
+func SanitizedForMessage(text string) string {
+	ret := strings.Replace(text, "%", "%%", -1)
+	return strings.Replace(ret, "${", "${"+dollarVarName+"}{", -1)
+}
+
+// GoTypeForDataType returns the Go type of the arguments of format
+// specifiers with the given data type.
+func GoTypeForDataType(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "string"
+	case model.DataTypeInteger:
+		return "int"
+	case model.DataTypeFloat:
+		return "float64"
+	}
+	return "interface{}"
+}
+
+func messageFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += SanitizedForMessage(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+func goDocComment(paragraphs []string) string {
+	ret := ""
+	for i, paragraph := range paragraphs {
+		if 0 < i {
+			ret += "//\n"
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			ret += strings.TrimRight("// "+line, " ") + "\n"
+		}
+	}
+	return ret
+}
+
+func goAccessor(translation model.Translation, sourceLanguage string, name string) string {
+	value := translation.ValueForLanguage(sourceLanguage)
+	var arguments []model.FormatSpecifierSegment
+	docParagraphs := []string{name + " returns the translation of " + strconv.Quote(translation.Key) + "."}
+	if value != nil {
+		docParagraphs[0] = name + " returns the translation of " + strconv.Quote(messageFromSegments(value.Segments)) + "."
+		arguments = value.Arguments()
+	}
+	if 0 < len(translation.Comment) {
+		docParagraphs = append(docParagraphs, translation.Comment)
+	}
+
+	parameters := []string{"p *message.Printer"}
+	sprintfArguments := []string{strconv.Quote(translation.Key)}
+	for index, argument := range arguments {
+		argumentName := model.ArgumentName(index)
+		parameters = append(parameters, argumentName+" "+GoTypeForDataType(argument.DataType))
+		sprintfArguments = append(sprintfArguments, argumentName)
+	}
+
+	return goDocComment(docParagraphs) +
+		"func " + name + "(" + strings.Join(parameters, ", ") + ") string {\n" +
+		"\treturn p.Sprintf(" + strings.Join(sprintfArguments, ", ") + ")\n" +
+		"}\n"
+}
+
+// GetGoFileContents returns the source code of the Go package.
+func GetGoFileContents(set model.TranslationSet) string {
+	sourceLanguage := ""
+	if 0 < len(set.Languages) {
+		sourceLanguage = set.Languages[0]
+	}
+
+	messages := ""
+	accessors := ""
+	needsDollarVar := false
+	namesUsed := make(map[string]string)
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			name := util.CamelCaseIdentifier(translation.Key, true)
+			if reservedNames[name] {
+				name += "_"
+			}
+			if originalKey, exists := namesUsed[name]; exists {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"': its Go name '"+name+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			namesUsed[name] = translation.Key
+
+			for _, language := range set.Languages {
+				value := translation.ValueForLanguage(language)
+				if value == nil {
+					continue
+				}
+				message := messageFromSegments(value.Segments)
+				needsDollarVar = needsDollarVar || strings.Contains(message, "${"+dollarVarName+"}")
+				messages += "\t{" + strconv.Quote(language) + ", " + strconv.Quote(translation.Key) + ", " + strconv.Quote(message) + "},\n"
+			}
+			accessors += "\n" + goAccessor(translation, sourceLanguage, name)
+		}
+	}
+
+	ret := "// Code generated by Sanat. DO NOT EDIT.\n\n" +
+		"package " + PackageName + "\n\n" +
+		"import (\n" +
+		"\t\"golang.org/x/text/language\"\n" +
+		"\t\"golang.org/x/text/message\"\n" +
+		"\t\"golang.org/x/text/message/catalog\"\n" +
+		")\n\n" +
+		"var messages = []struct{ language, key, message string }{\n" +
+		messages +
+		"}\n\n" +
+		"// Catalog contains the translations of all languages.\n" +
+		"var Catalog = newCatalog()\n\n" +
+		"func newCatalog() *catalog.Builder {\n" +
+		"\tb := catalog.NewBuilder(catalog.Fallback(language.Make(" + strconv.Quote(sourceLanguage) + ")))\n"
+	if needsDollarVar {
+		ret += "\tdollar := catalog.Var(" + strconv.Quote(dollarVarName) + ", catalog.String(\"$\"))\n" +
+			"\tfor _, m := range messages {\n" +
+			"\t\tif err := b.Set(language.Make(m.language), m.key, dollar, catalog.String(m.message)); err != nil {\n"
+	} else {
+		ret += "\tfor _, m := range messages {\n" +
+			"\t\tif err := b.SetString(language.Make(m.language), m.key, m.message); err != nil {\n"
+	}
+	ret += "\t\t\tpanic(err)\n" +
+		"\t\t}\n" +
+		"\t}\n" +
+		"\treturn b\n" +
+		"}\n\n" +
+		"// NewPrinter returns a printer that formats the translations for the\n" +
+		"// best match for the given language, or the fallback language.\n" +
+		"func NewPrinter(tag language.Tag) *message.Printer {\n" +
+		"\tmatched, _, _ := Catalog.Matcher().Match(tag)\n" +
+		"\treturn message.NewPrinter(matched, message.Catalog(Catalog))\n" +
+		"}\n" +
+		accessors
+	return ret
+}
+
+// GetGoFiles returns the contents of the file that WriteGoFile writes,
+// keyed by path relative to the output directory.
+func GetGoFiles(set model.TranslationSet) map[string]string {
+	return map[string]string{PackageName + ".go": GetGoFileContents(set)}
+}
+
+func WriteGoFile(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetGoFiles(set))
+}
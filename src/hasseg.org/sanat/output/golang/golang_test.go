@@ -0,0 +1,144 @@
+package golang_test
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/golang"
+	"hasseg.org/sanat/test"
+)
+
+func TestGoFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return golang.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "%[1]v", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "%[1]s", val(model.DataTypeString, 0, -1, -1), "")
+	assert.Equal(t, "%[2]d", val(model.DataTypeInteger, 1, -1, -1), "")
+	assert.Equal(t, "%[1]v", val(model.DataTypeFloat, 0, -1, -1), "")
+	assert.Equal(t, "%.2[1]f", val(model.DataTypeFloat, 0, 2, -1), "")
+	assert.Equal(t, "%[1]s", val(model.DataTypeString, 0, 2, -1), "Decimal count is only for floats")
+	assert.Equal(t, "%[12]s", val(model.DataTypeString, 2, -1, 12), "Explicit order index overrides actual position")
+}
+
+func TestTextSanitizedForGoMessage(t *testing.T) {
+	assert.Equal(t, "Foo", golang.SanitizedForMessage("Foo"), "")
+	assert.Equal(t, "100%% done", golang.SanitizedForMessage("100% done"), "")
+	assert.Equal(t, "Costs $5, not ${dollar}{x}", golang.SanitizedForMessage("Costs $5, not ${x}"), "")
+}
+
+func TestGoFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	title := section.AddTranslation("LoginView.Title")
+	title.Comment = "Page title"
+	title.AddValue("en", []model.Segment{model.NewTextSegment("Log in")})
+	title.AddValue("fi", []model.Segment{model.NewTextSegment("Kirjaudu")})
+	greeting := section.AddTranslation("LoginView.Greeting")
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+	})
+	section.AddTranslation("LoginView title").AddValue("en", []model.Segment{model.NewTextSegment("Collides")})
+	section.AddTranslation("Catalog").AddValue("en", []model.Segment{model.NewTextSegment("Reserved")})
+	appleOnly := section.AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en")
+	ts.AddLanguage("fi")
+
+	golang.PackageName = "strings"
+	defer func() { golang.PackageName = "translations" }()
+
+	assert.Equal(t, `// Code generated by Sanat. DO NOT EDIT.
+
+package strings
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+var messages = []struct{ language, key, message string }{
+	{"en", "LoginView.Title", "Log in"},
+	{"fi", "LoginView.Title", "Kirjaudu"},
+	{"en", "LoginView.Greeting", "Hi %[2]s, you owe %.2[1]f"},
+	{"en", "Catalog", "Reserved"},
+}
+
+// Catalog contains the translations of all languages.
+var Catalog = newCatalog()
+
+func newCatalog() *catalog.Builder {
+	b := catalog.NewBuilder(catalog.Fallback(language.Make("en")))
+	for _, m := range messages {
+		if err := b.SetString(language.Make(m.language), m.key, m.message); err != nil {
+			panic(err)
+		}
+	}
+	return b
+}
+
+// NewPrinter returns a printer that formats the translations for the
+// best match for the given language, or the fallback language.
+func NewPrinter(tag language.Tag) *message.Printer {
+	matched, _, _ := Catalog.Matcher().Match(tag)
+	return message.NewPrinter(matched, message.Catalog(Catalog))
+}
+
+// LoginViewTitle returns the translation of "Log in".
+//
+// Page title
+func LoginViewTitle(p *message.Printer) string {
+	return p.Sprintf("LoginView.Title")
+}
+
+// LoginViewGreeting returns the translation of "Hi %[2]s, you owe %.2[1]f".
+func LoginViewGreeting(p *message.Printer, arg1 float64, arg2 string) string {
+	return p.Sprintf("LoginView.Greeting", arg1, arg2)
+}
+
+// Catalog_ returns the translation of "Reserved".
+func Catalog_(p *message.Printer) string {
+	return p.Sprintf("Catalog")
+}
+`, golang.GetGoFileContents(ts), "")
+
+	_, exists := golang.GetGoFiles(ts)["strings.go"]
+	assert.True(t, exists, "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	for filePath, contents := range golang.GetGoFiles(set) {
+		_, err := parser.ParseFile(token.NewFileSet(), filePath, contents, 0)
+		assert.Nil(t, err, filePath)
+	}
+}
+
+func TestGoOptions(t *testing.T) {
+	ass := func(isValid bool, packageName string) {
+		golang.PackageName = packageName
+		defer func() { golang.PackageName = "translations" }()
+		assert.Equal(t, isValid, golang.CheckOptions() == nil, packageName)
+	}
+
+	ass(true, "translations")
+	ass(true, "strings_fi")
+	ass(false, "")
+	ass(false, "_")
+	ass(false, "com.example.app")
+	ass(false, "my-strings")
+	ass(false, "func")
+}
@@ -0,0 +1,113 @@
+package icu
+
+import (
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// FormatSpecifierStringForFormatSpecifier returns an ICU MessageFormat
+// argument for the format specifier, given its 0-based position among the
+// format specifiers in its value. Decimal counts are expressed as number
+// skeletons, which both ICU4J and FormatJS understand.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	ret := "{" + strconv.Itoa(segment.ArgumentIndex(position))
+	if segment.DataType == model.DataTypeInteger {
+		ret += ", number, integer"
+	} else if segment.DataType == model.DataTypeFloat {
+		ret += ", number"
+		if 0 == segment.NumberOfDecimals {
+			ret += ", ::precision-integer"
+		} else if 0 < segment.NumberOfDecimals {
+			ret += ", ::." + strings.Repeat("0", segment.NumberOfDecimals)
+		}
+	}
+	return ret + "}"
+}
+
+// SanitizedForMessageText quotes the text so that MessageFormat parsers
+// read it literally: apostrophes are doubled and curly braces are
+// wrapped in apostrophes. An apostrophe between two braces stays inside
+// the same quoted run (as a doubled apostrophe) instead of closing and
+// reopening it; the latter would read back as two apostrophes.
+//
+// The result is valid for both ICU and java.text.MessageFormat.
+func SanitizedForMessageText(text string) string {
+	ret := ""
+	quoting := false
+	for _, c := range text {
+		switch c {
+		case '{', '}':
+			if !quoting {
+				ret += "'"
+				quoting = true
+			}
+			ret += string(c)
+		case '\'':
+			ret += "''"
+		default:
+			if quoting {
+				ret += "'"
+				quoting = false
+			}
+			ret += string(c)
+		}
+	}
+	if quoting {
+		ret += "'"
+	}
+	return ret
+}
+
+// MessageFromSegments renders the segments of a translation value as an
+// ICU MessageFormat string.
+func MessageFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += SanitizedForMessageText(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+// GetMessagesFileContents returns a JSON object mapping each translation
+// key to its ICU MessageFormat message in the given language.
+func GetMessagesFileContents(set model.TranslationSet, language string) string {
+	messages := util.NewOrderedMap()
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+			messages.Set(translation.Key, MessageFromSegments(value.Segments))
+		}
+	}
+	return util.IndentedJSON(messages)
+}
+
+// GetMessagesFiles returns the contents of all the files that
+// WriteMessagesFiles writes, keyed by path relative to the output
+// directory.
+func GetMessagesFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[language+".json"] = GetMessagesFileContents(set, language)
+	}
+	return ret
+}
+
+func WriteMessagesFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetMessagesFiles(set))
+}
@@ -0,0 +1,95 @@
+package icu_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/icu"
+	"hasseg.org/sanat/test"
+)
+
+func TestICUFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return icu.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	// Data types
+	assert.Equal(t, "{0}", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "{0}", val(model.DataTypeString, 0, -1, -1), "")
+	assert.Equal(t, "{0, number}", val(model.DataTypeFloat, 0, -1, -1), "")
+	assert.Equal(t, "{0, number, integer}", val(model.DataTypeInteger, 0, -1, -1), "")
+
+	// Semantic order index
+	assert.Equal(t, "{3}", val(model.DataTypeObject, 3, -1, -1), "")
+	assert.Equal(t, "{0}", val(model.DataTypeObject, 3, -1, 1), "Output index is 0-based while explicit order index is 1-based")
+	assert.Equal(t, "{11}", val(model.DataTypeObject, 3, -1, 12), "Explicit order index overrides actual position")
+
+	// Decimal count
+	assert.Equal(t, "{0, number, ::precision-integer}", val(model.DataTypeFloat, 0, 0, -1), "")
+	assert.Equal(t, "{0, number, ::.0}", val(model.DataTypeFloat, 0, 1, -1), "")
+	assert.Equal(t, "{0, number, ::.00}", val(model.DataTypeFloat, 0, 2, -1), "")
+	assert.Equal(t, "{2, number, ::.0}", val(model.DataTypeFloat, 0, 1, 3), "Decimal count together with semantic order index")
+	assert.Equal(t, "{0}", val(model.DataTypeString, 0, 2, -1), "Decimal count is only for floats")
+	assert.Equal(t, "{0, number, integer}", val(model.DataTypeInteger, 0, 2, -1), "Decimal count is only for floats")
+}
+
+func TestTextSanitizedForICUMessage(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, icu.SanitizedForMessageText(input), input)
+	}
+
+	ass("", "")
+	ass("Foo", "Foo")
+
+	// Escaping curly braces with single quotes
+	ass("eka '{}' toka", "eka {} toka")
+	ass("eka '{{}}' toka", "eka {{}} toka")
+	ass("eka '{' toka", "eka { toka")
+	ass("eka '{'0'}' toka", "eka {0} toka")
+	ass("eka '{' keski moro '}' toka", "eka { keski moro } toka")
+
+	// Escaping single quotes themselves
+	ass("eka '' toka", "eka ' toka")
+	ass("eka '''{}''' toka", "eka '{}' toka")
+	ass("eka '{''}' toka", "eka {'} toka")
+	ass("eka '{''''}' toka", "eka {''} toka")
+}
+
+func TestMessageFromSegments(t *testing.T) {
+	assert.Equal(t, "It''s {0} at {1, number, ::.0} '{'°C'}'",
+		icu.MessageFromSegments([]model.Segment{
+			model.NewTextSegment("It's "),
+			model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+			model.NewTextSegment(" at "),
+			model.NewFormatSpecifierSegment(model.DataTypeFloat, 1, -1),
+			model.NewTextSegment(" {°C}"),
+		}), "Format specifiers are numbered by position, ignoring text segments")
+}
+
+func TestMessagesFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	section.AddTranslation("Hello").AddValue("en", []model.Segment{model.NewTextSegment("Hello")})
+	appleOnly := section.AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en")
+
+	assert.Equal(t, "{\n  \"Hello\": \"Hello\"\n}\n", icu.GetMessagesFileContents(ts, "en"), "Translations limited to platforms are not written")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	files := icu.GetMessagesFiles(set)
+	assert.Equal(t, len(set.Languages), len(files), "")
+	for filePath, contents := range files {
+		var messages map[string]string
+		assert.Nil(t, json.Unmarshal([]byte(contents), &messages), filePath)
+	}
+}
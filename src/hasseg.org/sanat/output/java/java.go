@@ -2,13 +2,11 @@ package java
 
 import (
 	"fmt"
-	"os"
-	"path"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/icu"
 	"hasseg.org/sanat/util"
 )
 
@@ -34,33 +32,42 @@ func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegmen
 	return ret
 }
 
-var _moustacheRegexp *regexp.Regexp = nil
-
 func SanitizedForStringValue(text string) string {
 	// The escaping/quoting rules for Java MessageFormat strings are a
-	// MASSIVE PAIN IN THE ASS. Let’s just do our best.
+	// MASSIVE PAIN IN THE ASS. They're the same as for ICU though, so
+	// let's just share the implementation.
 	// https://docs.oracle.com/javase/7/docs/api/java/text/MessageFormat.html
 	//
-	if _moustacheRegexp == nil {
-		_moustacheRegexp, _ = regexp.Compile("([{}]+)")
-	}
-	s := ""
-	for index, part := range strings.Split(text, "'") {
-		p := _moustacheRegexp.ReplaceAllString(part, "'$1'")
-		if index == 0 || strings.HasSuffix(s, "'") && strings.HasPrefix(p, "'") {
-			s += p
-		} else {
-			s += "''" + p
-		}
-	}
-	return util.XMLEscaped(s)
+	return util.XMLEscaped(icu.SanitizedForMessageText(text))
 }
 
 func SanitizedForKey(text string) string {
 	return util.XMLEscaped(text)
 }
 
-func stringFromSegments(segments []model.Segment) string {
+// messageFormatStringFromSegments renders the segments as a MessageFormat
+// pattern. The arguments are numbered by their position among the format
+// specifiers (text segments don't count.)
+func messageFormatStringFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += icu.SanitizedForMessageText(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+// StringFromSegments renders the segments as a value for the XML
+// properties files. Note that unlike in messageFormatStringFromSegments,
+// the arguments are numbered by the index of the segment, as they have
+// always been in this format.
+func StringFromSegments(segments []model.Segment) string {
 	ret := ""
 	for index, segment := range segments {
 		switch segment.(type) {
@@ -105,25 +112,24 @@ func GetPropertiesFileContents(set model.TranslationSet, language string) string
 				sectionHeadingPrinted = true
 			}
 
-			ret += fmt.Sprintf("  <entry key=\"%s\">%s</entry>\n", SanitizedForKey(translation.Key), stringFromSegments(value.Segments))
+			ret += fmt.Sprintf("  <entry key=\"%s\">%s</entry>\n", SanitizedForKey(translation.Key), StringFromSegments(value.Segments))
 		}
 	}
 	ret += "</properties>\n"
 	return ret
 }
 
-func WritePropertiesFiles(set model.TranslationSet, outDirPath string) {
-	for language, _ := range set.Languages {
-		os.MkdirAll(outDirPath, 0777)
-
-		f, err := os.Create(path.Join(outDirPath, "Properties_"+language+".xml"))
-		if err != nil {
-			panic(err)
-		}
-
-		_, err = f.WriteString(GetPropertiesFileContents(set, language))
-		if err != nil {
-			panic(err)
-		}
+// GetPropertiesFiles returns the contents of all the files that
+// WritePropertiesFiles writes, keyed by path relative to the output
+// directory.
+func GetPropertiesFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret["Properties_"+language+".xml"] = GetPropertiesFileContents(set, language)
 	}
+	return ret
+}
+
+func WritePropertiesFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetPropertiesFiles(set))
 }
@@ -71,6 +71,16 @@ func TestTextSanitizedForJavaString(t *testing.T) {
 	ass("&lt;Foo&gt;", "<Foo>")
 }
 
+func TestJavaStringFromSegments(t *testing.T) {
+	segments := []model.Segment{
+		model.NewTextSegment("Hello "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(" & "),
+		model.NewFormatSpecifierSegment(model.DataTypeInteger, -1, -1),
+	}
+	assert.Equal(t, "Hello {1} &amp; {3,number,integer}", java.StringFromSegments(segments), "Arguments are numbered by segment index")
+}
+
 func makeTranslationSet(sectionName string, keyName string, language string, value string) model.TranslationSet {
 	ts := model.NewTranslationSet()
 	ts.AddSection(sectionName).AddTranslation(keyName).AddValue(language, []model.Segment{model.NewTextSegment(value)})
@@ -94,7 +104,7 @@ func TestOverallXMLFileGeneration(t *testing.T) {
 
 func TestComprehensiveInput(t *testing.T) {
 	set := test.GetComprehensiveTestInputTranslationSet()
-	for language, _ := range set.Languages {
+	for _, language := range set.Languages {
 		output := java.GetPropertiesFileContents(set, language)
 		assert.True(t, util.XMLIsValid(output), language)
 	}
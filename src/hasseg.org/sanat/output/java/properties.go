@@ -0,0 +1,225 @@
+package java
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// BundleBaseName is the ResourceBundle base name used for the file names
+// of the "classic" .properties files (e.g. `Messages_fi_FI.properties`.)
+var BundleBaseName = "Messages"
+
+// Lines longer than this are continued on the next line (if they can
+// be split at a space.)
+const propertiesMaxLineLength = 80
+
+func isAllLetters(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllDigits(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// BundleSuffixForLanguage turns a BCP 47 language identifier into the
+// form ResourceBundle uses in bundle names, as in
+// `ResourceBundle.Control.toBundleName()`: language, script, country and
+// variant separated by underscores, with empty parts kept in the middle
+// (e.g. "fi-FI" → "fi_FI", "zh-Hant-TW" → "zh_Hant_TW", "de-1996" →
+// "de__1996".) Note that this is not the same as `Locale.toString()`
+// ("zh_TW_#Hant".) Extension and private use subtags are dropped, since
+// bundle names can't have them.
+func BundleSuffixForLanguage(language string) string {
+	subtags := strings.Split(strings.Replace(language, "_", "-", -1), "-")
+	lang := strings.ToLower(subtags[0])
+	script := ""
+	country := ""
+	variants := make([]string, 0)
+	for i, subtag := range subtags[1:] {
+		if len(subtag) <= 1 {
+			break // Extension or private use subtags follow
+		}
+		if i == 0 && len(subtag) == 4 && isAllLetters(subtag) {
+			script = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		} else if len(country) == 0 && len(variants) == 0 &&
+			(len(subtag) == 2 && isAllLetters(subtag) || len(subtag) == 3 && isAllDigits(subtag)) {
+			country = strings.ToUpper(subtag)
+		} else {
+			variants = append(variants, subtag)
+		}
+	}
+	variant := strings.Join(variants, "_")
+
+	parts := []string{lang}
+	if 0 < len(script) {
+		parts = append(parts, script)
+	}
+	if 0 < len(variant) {
+		parts = append(parts, country, variant)
+	} else if 0 < len(country) {
+		parts = append(parts, country)
+	}
+	return strings.Join(parts, "_")
+}
+
+// escapedForISO88591 escapes the characters that can't be written as-is
+// into an ISO-8859-1 encoded .properties file (non-ASCII and control
+// characters) as \uXXXX sequences.
+func escapedForISO88591(c rune) string {
+	if 0x20 <= c && c <= 0x7e {
+		return string(c)
+	}
+	ret := ""
+	for _, unit := range utf16.Encode([]rune{c}) {
+		ret += fmt.Sprintf("\\u%04X", unit)
+	}
+	return ret
+}
+
+func escapedForProperties(text string, isKey bool) string {
+	ret := ""
+	for i, c := range text {
+		switch c {
+		case '\\':
+			ret += "\\\\"
+		case '\n':
+			ret += "\\n"
+		case '\r':
+			ret += "\\r"
+		case '\t':
+			ret += "\\t"
+		case '\f':
+			ret += "\\f"
+		case ' ':
+			if isKey || i == 0 {
+				ret += "\\ "
+			} else {
+				ret += " "
+			}
+		case '=', ':', '#', '!':
+			if isKey {
+				ret += "\\"
+			}
+			ret += string(c)
+		default:
+			ret += escapedForISO88591(c)
+		}
+	}
+	return ret
+}
+
+// SanitizedForPropertiesValue escapes the text for use as a value in a
+// "classic" .properties file. Note that this does not do the
+// MessageFormat quoting.
+func SanitizedForPropertiesValue(text string) string {
+	return escapedForProperties(text, false)
+}
+
+func SanitizedForPropertiesKey(text string) string {
+	return escapedForProperties(text, true)
+}
+
+func sanitizedForPropertiesComment(text string) string {
+	ret := ""
+	for _, c := range strings.Replace(text, "\n", " ", -1) {
+		ret += escapedForISO88591(c)
+	}
+	return ret
+}
+
+// wrappedPropertiesLine returns a `key = value` line, continuing long
+// values on the following lines. The value is only split after spaces
+// (that are not followed by more whitespace), since leading whitespace
+// on continuation lines is ignored when the file is read.
+func wrappedPropertiesLine(escapedKey string, escapedValue string) string {
+	ret := escapedKey + " = "
+	lineStart := 0
+	lineStartColumn := len(ret)
+	breakAfter := -1
+	for i := 0; i < len(escapedValue); i++ {
+		if 0 < i && i < len(escapedValue)-1 &&
+			escapedValue[i] == ' ' && escapedValue[i-1] != '\\' &&
+			!util.IsWhitespace(escapedValue[i+1]) {
+			breakAfter = i
+		}
+		if propertiesMaxLineLength < lineStartColumn+i+1-lineStart && lineStart <= breakAfter {
+			ret += escapedValue[lineStart:breakAfter+1] + "\\\n    "
+			lineStart = breakAfter + 1
+			lineStartColumn = 4
+			breakAfter = -1
+		}
+	}
+	return ret + escapedValue[lineStart:] + "\n"
+}
+
+// GetClassicPropertiesFileContents returns the contents of an
+// ISO-8859-1 encoded .properties file, as read by `Properties.load()`
+// and `ResourceBundle.getBundle()`. The values are MessageFormat
+// patterns, like in the XML properties files.
+func GetClassicPropertiesFileContents(set model.TranslationSet, language string) string {
+	ret := "# Java Properties File\n" +
+		"# Generated by Sanat\n" +
+		"# Language: " + sanitizedForPropertiesComment(language) + "\n"
+
+	for _, section := range set.Sections {
+		sectionHeadingPrinted := false
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformJava) {
+				continue
+			}
+
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+
+			if !sectionHeadingPrinted && 0 < len(section.Name) {
+				ret += "\n# ********** " + sanitizedForPropertiesComment(section.Name) + " **********\n\n"
+				sectionHeadingPrinted = true
+			}
+
+			if 0 < len(translation.Comment) {
+				ret += "# " + sanitizedForPropertiesComment(translation.Comment) + "\n"
+			}
+			ret += wrappedPropertiesLine(
+				SanitizedForPropertiesKey(translation.Key),
+				SanitizedForPropertiesValue(messageFormatStringFromSegments(value.Segments)))
+		}
+	}
+	return ret
+}
+
+// GetClassicPropertiesFiles returns the contents of all the files that
+// WriteClassicPropertiesFiles writes, keyed by path relative to the
+// output directory. The first language is also written as the base
+// bundle (without a language suffix) so that ResourceBundle always has
+// something to fall back to.
+func GetClassicPropertiesFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for i, language := range set.Languages {
+		contents := GetClassicPropertiesFileContents(set, language)
+		ret[BundleBaseName+"_"+BundleSuffixForLanguage(language)+".properties"] = contents
+		if i == 0 {
+			ret[BundleBaseName+".properties"] = contents
+		}
+	}
+	return ret
+}
+
+func WriteClassicPropertiesFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetClassicPropertiesFiles(set))
+}
@@ -0,0 +1,101 @@
+package java_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/java"
+	"hasseg.org/sanat/test"
+)
+
+func TestTextSanitizedForPropertiesValue(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, java.SanitizedForPropertiesValue(input), input)
+	}
+
+	ass("", "")
+	ass("Foo", "Foo")
+	ass("foo = bar: #1!", "foo = bar: #1!")
+	ass("\\ leading space", " leading space")
+	ass("back\\\\slash", "back\\slash")
+	ass("line\\nbreak\\ttab", "line\nbreak\ttab")
+	ass("\\u00E4iti", "äiti")
+	ass("\\u20AC", "€")
+	ass("\\uD83D\\uDE00", "😀")
+}
+
+func TestTextSanitizedForPropertiesKey(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, java.SanitizedForPropertiesKey(input), input)
+	}
+
+	ass("Foo.Bar", "Foo.Bar")
+	ass("a\\ b", "a b")
+	ass("a\\=b\\:c", "a=b:c")
+	ass("\\#a\\!", "#a!")
+}
+
+func TestBundleSuffixForLanguage(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, java.BundleSuffixForLanguage(input), input)
+	}
+
+	ass("fi", "fi")
+	ass("fi_FI", "fi-FI")
+	ass("fi_FI", "fi_fi")
+	ass("es_419", "es-419")
+	ass("zh_Hant", "zh-Hant")
+	ass("zh_Hant_TW", "zh-hant-tw")
+	ass("de__1996", "de-1996")
+	ass("sl_IT_nedis", "sl-IT-nedis")
+	ass("sr_Latn_RS_ekavsk", "sr-Latn-RS-ekavsk")
+	ass("en_US", "en-US-u-ca-gregory")
+	ass("en", "en-x-pseudo")
+}
+
+func TestClassicPropertiesFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("Main")
+	greeting := section.AddTranslation("greeting")
+	greeting.Comment = "Shown at launch"
+	greeting.AddValue("fi-FI", []model.Segment{
+		model.NewTextSegment("Hei "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(", se on {kivaa}"),
+	})
+	section.AddTranslation("long").AddValue("fi-FI", []model.Segment{
+		model.NewTextSegment(strings.Repeat("sana ", 30) + "loppu"),
+	})
+	ts.AddLanguage("fi-FI")
+
+	assert.Equal(t, `# Java Properties File
+# Generated by Sanat
+# Language: fi-FI
+
+# ********** Main **********
+
+# Shown at launch
+greeting = Hei {0}, se on '{'kivaa'}'
+long = sana sana sana sana sana sana sana sana sana sana sana sana sana sana \
+    sana sana sana sana sana sana sana sana sana sana sana sana sana sana sana \
+    sana loppu
+`, java.GetClassicPropertiesFileContents(ts, "fi-FI"), "")
+
+	files := java.GetClassicPropertiesFiles(ts)
+	assert.Equal(t, 2, len(files), "")
+	assert.Equal(t, files["Messages.properties"], files["Messages_fi_FI.properties"], "")
+}
+
+func TestClassicPropertiesComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	files := java.GetClassicPropertiesFiles(set)
+	assert.Equal(t, len(set.Languages)+1, len(files), "")
+	for filePath, contents := range files {
+		for _, c := range contents {
+			assert.True(t, c < 0x80, filePath)
+		}
+	}
+}
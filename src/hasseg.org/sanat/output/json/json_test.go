@@ -0,0 +1,52 @@
+package json_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/model/jsondoc"
+	sanatjson "hasseg.org/sanat/output/json"
+	"hasseg.org/sanat/test"
+)
+
+func makeTranslationSet(sectionName string, keyName string, language string, value string) model.TranslationSet {
+	ts := model.NewTranslationSet()
+	ts.AddSection(sectionName).AddTranslation(keyName).AddValue(language, []model.Segment{model.NewTextSegment(value)})
+	ts.AddLanguage(language)
+	return ts
+}
+
+func TestSpecialCharactersAreEscaped(t *testing.T) {
+	ass := func(text string) {
+		output := sanatjson.GetJSONFileContents(makeTranslationSet(text, text, "en", text))
+
+		var document jsondoc.Document
+		err := json.Unmarshal([]byte(output), &document)
+		assert.Nil(t, err, text)
+		if err == nil {
+			assert.Equal(t, text, document.Sections[0].Name, text)
+			assert.Equal(t, text, document.Sections[0].Translations[0].Key, text)
+			assert.Equal(t, text, *document.Sections[0].Translations[0].Values[0].Segments[0].Text, text)
+		}
+	}
+
+	ass(`Double "quotes"`)
+	ass(`Back\slash`)
+	ass("New\nline")
+	ass("Control \x01 character")
+	ass("<b>Markup</b> & more")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	output := sanatjson.GetJSONFileContents(set)
+
+	var document jsondoc.Document
+	assert.Nil(t, json.Unmarshal([]byte(output), &document), "")
+	assert.Equal(t, jsondoc.SchemaVersion, document.Version, "")
+	assert.Equal(t, set.Languages, document.Languages, "")
+	assert.Equal(t, len(set.Sections), len(document.Sections), "")
+}
@@ -2,26 +2,58 @@ package output
 
 import (
 	"errors"
+	"path"
+	"sort"
 
 	"hasseg.org/sanat/model"
 	"hasseg.org/sanat/output/android"
 	"hasseg.org/sanat/output/apple"
+	"hasseg.org/sanat/output/arb"
 	"hasseg.org/sanat/output/dump"
+	"hasseg.org/sanat/output/fluent"
+	"hasseg.org/sanat/output/golang"
+	"hasseg.org/sanat/output/icu"
 	"hasseg.org/sanat/output/java"
 	"hasseg.org/sanat/output/json"
+	"hasseg.org/sanat/output/qt"
+	"hasseg.org/sanat/output/web"
 	"hasseg.org/sanat/output/windows"
+	"hasseg.org/sanat/output/xliff"
+	"hasseg.org/sanat/output/yaml"
+	"hasseg.org/sanat/util"
 )
 
 type OutputFunction func(model.TranslationSet, string)
 
 var OutputFunctionsByName = map[string]OutputFunction{
-	"apple":        apple.WriteStringsFiles,
-	"android":      android.WriteStringsFiles,
-	"windows-resx": windows.WriteResxStringsFiles,
-	"windows-resw": windows.WriteReswStringsFiles,
-	"java":         java.WritePropertiesFiles,
-	"json":         json.DumpTranslationSet,
-	"dump":         dump.DumpTranslationSet,
+	"apple":                  apple.WriteStringsFiles,
+	"apple-xcstrings":        apple.WriteStringCatalogFile,
+	"swift":                  apple.WriteSwiftFile,
+	"android":                android.WriteStringsFiles,
+	"kotlin":                 android.WriteKotlinFile,
+	"windows-resx":           windows.WriteResxStringsFiles,
+	"windows-resw":           windows.WriteReswStringsFiles,
+	"windows-resx-satellite": windows.WriteSatelliteResxStringsFiles,
+	"csharp":                 windows.WriteCSharpFile,
+	"csharp-resw":            windows.WriteCSharpResourceLoaderFile,
+	"go":                     golang.WriteGoFile,
+	"java":                   java.WritePropertiesFiles,
+	"java-properties":        java.WriteClassicPropertiesFiles,
+	"json":                   json.WriteJSONFile,
+	"dump":                   dump.DumpTranslationSet,
+	"icu":                    icu.WriteMessagesFiles,
+	"arb":                    arb.WriteARBFiles,
+	"qt":                     qt.WriteTSFiles,
+	"yaml":                   yaml.WriteYAMLFiles,
+	"fluent":                 fluent.WriteFTLFiles,
+	"xliff":                  xliff.WriteXLIFFFiles,
+
+	"i18next":         web.WriteI18nextFiles,
+	"i18next-nested":  web.WriteNestedI18nextFiles,
+	"formatjs":        web.WriteFormatJSFiles,
+	"formatjs-nested": web.WriteNestedFormatJSFiles,
+	"webextension":    web.WriteWebExtensionFiles,
+	"typescript":      web.WriteTypeScriptFiles,
 }
 
 func OutputFunctionForName(name string) (OutputFunction, error) {
@@ -36,3 +68,92 @@ func OutputFunctionForName(name string) (OutputFunction, error) {
 	}
 	return nil, errors.New(e)
 }
+
+// OptionsCheckFunctionsByName has, for the output formats whose options
+// (package variables like golang.PackageName) have to be set in a
+// certain way, functions that return an error if they aren't.
+var OptionsCheckFunctionsByName = map[string]func() error{
+	"kotlin": android.CheckKotlinOptions,
+	"go":     golang.CheckOptions,
+}
+
+// CheckOptionsForFormat returns an error if the output format can't be
+// generated with the current options.
+func CheckOptionsForFormat(name string) error {
+	if checkFunction := OptionsCheckFunctionsByName[name]; checkFunction != nil {
+		return checkFunction()
+	}
+	return nil
+}
+
+// FilesFunction renders output files in memory, returning their contents
+// keyed by path relative to the output directory.
+type FilesFunction func(model.TranslationSet) map[string]string
+
+var FilesFunctionsByName = map[string]FilesFunction{
+	"apple":                  apple.GetStringsFiles,
+	"apple-xcstrings":        apple.GetStringCatalogFiles,
+	"swift":                  apple.GetSwiftFiles,
+	"android":                android.GetStringsFiles,
+	"kotlin":                 android.GetKotlinFiles,
+	"windows-resx":           windows.GetResxStringsFiles,
+	"windows-resw":           windows.GetReswStringsFiles,
+	"windows-resx-satellite": windows.GetSatelliteResxStringsFiles,
+	"csharp":                 windows.GetCSharpFiles,
+	"csharp-resw":            windows.GetCSharpResourceLoaderFiles,
+	"go":                     golang.GetGoFiles,
+	"java":                   java.GetPropertiesFiles,
+	"java-properties":        java.GetClassicPropertiesFiles,
+	"json":                   json.GetJSONFiles,
+	"icu":                    icu.GetMessagesFiles,
+	"arb":                    arb.GetARBFiles,
+	"qt":                     qt.GetTSFiles,
+	"yaml":                   yaml.GetYAMLFiles,
+	"fluent":                 fluent.GetFTLFiles,
+	"xliff":                  xliff.GetXLIFFFiles,
+
+	"i18next":         web.GetI18nextFiles,
+	"i18next-nested":  web.GetNestedI18nextFiles,
+	"formatjs":        web.GetFormatJSFiles,
+	"formatjs-nested": web.GetNestedFormatJSFiles,
+	"webextension":    web.GetWebExtensionFiles,
+	"typescript":      web.GetTypeScriptFiles,
+}
+
+func FilesFunctionForName(name string) (FilesFunction, error) {
+	ret := FilesFunctionsByName[name]
+	if ret != nil {
+		return ret, nil
+	}
+
+	e := "Output format '" + name + "' cannot be rendered in memory — supported formats: "
+	for formatName, _ := range FilesFunctionsByName {
+		e += formatName + " "
+	}
+	return nil, errors.New(e)
+}
+
+// DiffsForOutdatedFiles compares the given rendered files against the
+// ones on disk in outDirPath and returns a unified diff for each file
+// that differs (or is missing), ordered by path.
+func DiffsForOutdatedFiles(files map[string]string, outDirPath string) []string {
+	relativePaths := make([]string, 0, len(files))
+	for relativePath, _ := range files {
+		relativePaths = append(relativePaths, relativePath)
+	}
+	sort.Strings(relativePaths)
+
+	ret := make([]string, 0)
+	for _, relativePath := range relativePaths {
+		contents := files[relativePath]
+		fromName := path.Join("a", relativePath)
+		existingContents, exists := util.FileContents(path.Join(outDirPath, relativePath))
+		if !exists {
+			fromName = "/dev/null"
+		}
+		if !exists || existingContents != contents {
+			ret = append(ret, util.UnifiedDiff(fromName, path.Join("b", relativePath), existingContents, contents))
+		}
+	}
+	return ret
+}
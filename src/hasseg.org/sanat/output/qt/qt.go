@@ -0,0 +1,108 @@
+package qt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// Qt Linguist .ts files contain the source text along with the
+// translation, so one language of the set (the first one) is used as the
+// source language. The translation keys are written as message IDs, to
+// be used with `qtTrId()`.
+//
+// Qt's numerus forms aren't written since translations don't have plural
+// forms.
+
+// FormatSpecifierStringForFormatSpecifier returns the `QString::arg()`
+// place marker for the format specifier. The number of decimals can't
+// be expressed in the marker; it has to be passed to `arg()` instead.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	return "%" + strconv.Itoa(segment.ArgumentIndex(position)+1)
+}
+
+// LocaleForLanguage turns a BCP 47 language identifier into the
+// underscore-separated form used in the `language` attributes and the
+// file names.
+func LocaleForLanguage(language string) string {
+	return strings.Replace(language, "-", "_", -1)
+}
+
+func stringFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += util.XMLEscaped(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+func GetTSFileContents(set model.TranslationSet, language string) string {
+	sourceLanguage := ""
+	if 0 < len(set.Languages) {
+		sourceLanguage = set.Languages[0]
+	}
+
+	ret := "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<!DOCTYPE TS>\n"
+	ret += fmt.Sprintf("<TS version=\"2.1\" language=\"%s\" sourcelanguage=\"%s\">\n",
+		util.XMLEscaped(LocaleForLanguage(language)),
+		util.XMLEscaped(LocaleForLanguage(sourceLanguage)))
+	for _, section := range set.Sections {
+		messages := ""
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			messages += "    <message id=\"" + util.XMLEscaped(translation.Key) + "\">\n"
+
+			source := ""
+			if sourceValue := translation.ValueForLanguage(sourceLanguage); sourceValue != nil {
+				source = stringFromSegments(sourceValue.Segments)
+			}
+			messages += "        <source>" + source + "</source>\n"
+
+			if 0 < len(translation.Comment) {
+				messages += "        <extracomment>" + util.XMLEscaped(translation.Comment) + "</extracomment>\n"
+			}
+
+			value := translation.ValueForLanguage(language)
+			if value == nil || len(value.Segments) == 0 {
+				messages += "        <translation type=\"unfinished\"></translation>\n"
+			} else {
+				messages += "        <translation>" + stringFromSegments(value.Segments) + "</translation>\n"
+			}
+			messages += "    </message>\n"
+		}
+		if 0 < len(messages) {
+			ret += "<context>\n"
+			ret += "    <name>" + util.XMLEscaped(section.Name) + "</name>\n"
+			ret += messages
+			ret += "</context>\n"
+		}
+	}
+	ret += "</TS>\n"
+	return ret
+}
+
+// GetTSFiles returns the contents of all the files that WriteTSFiles
+// writes, keyed by path relative to the output directory.
+func GetTSFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[LocaleForLanguage(language)+".ts"] = GetTSFileContents(set, language)
+	}
+	return ret
+}
+
+func WriteTSFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetTSFiles(set))
+}
@@ -0,0 +1,78 @@
+package qt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/qt"
+	"hasseg.org/sanat/test"
+	"hasseg.org/sanat/util"
+)
+
+func TestQtFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return qt.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "%1", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "%1", val(model.DataTypeFloat, 0, 2, -1), "Decimal count is passed to arg() instead")
+	assert.Equal(t, "%3", val(model.DataTypeInteger, 2, -1, -1), "")
+	assert.Equal(t, "%12", val(model.DataTypeString, 2, -1, 12), "Explicit order index overrides actual position")
+}
+
+func TestTSFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("Main <window>")
+	greeting := section.AddTranslation("greeting")
+	greeting.Comment = "Shown at launch"
+	greeting.AddValue("en-US", []model.Segment{
+		model.NewTextSegment("Hello "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(" & welcome"),
+	})
+	greeting.AddValue("fi-FI", []model.Segment{
+		model.NewTextSegment("Hei "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+	})
+	section.AddTranslation("untranslated").AddValue("en-US", []model.Segment{model.NewTextSegment("Later")})
+	appleOnly := ts.AddSection("Platform specific").AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en-US", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en-US")
+	ts.AddLanguage("fi-FI")
+
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE TS>
+<TS version="2.1" language="fi_FI" sourcelanguage="en_US">
+<context>
+    <name>Main &lt;window&gt;</name>
+    <message id="greeting">
+        <source>Hello %1 &amp; welcome</source>
+        <extracomment>Shown at launch</extracomment>
+        <translation>Hei %1</translation>
+    </message>
+    <message id="untranslated">
+        <source>Later</source>
+        <translation type="unfinished"></translation>
+    </message>
+</context>
+</TS>
+`, qt.GetTSFileContents(ts, "fi-FI"), "")
+
+	files := qt.GetTSFiles(ts)
+	assert.Equal(t, 2, len(files), "")
+	_, exists := files["en_US.ts"]
+	assert.True(t, exists, "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	for _, language := range set.Languages {
+		assert.True(t, util.XMLIsValid(qt.GetTSFileContents(set, language)), language)
+	}
+}
@@ -0,0 +1,233 @@
+package web
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// The TypeScript output consists of a `messages.d.ts` file declaring a
+// `Messages` interface with a function for each translation, and a
+// `messages.<language>.ts` module for each language whose default export
+// implements it. A translation missing from one of the languages (or
+// taking arguments of different types) is thus a type error.
+
+const typeScriptModuleName = "messages"
+
+// TypeScriptTypeForDataType returns the TypeScript type of the arguments
+// of format specifiers with the given data type.
+func TypeScriptTypeForDataType(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "string"
+	case model.DataTypeInteger, model.DataTypeFloat:
+		return "number"
+	}
+	return "unknown"
+}
+
+// TypeScriptFormatSpecifierStringForFormatSpecifier returns the template
+// literal substitution for the format specifier.
+func TypeScriptFormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	name := model.ArgumentName(segment.ArgumentIndex(position))
+	if segment.DataType == model.DataTypeFloat && 0 <= segment.NumberOfDecimals {
+		return "${" + name + ".toFixed(" + strconv.Itoa(segment.NumberOfDecimals) + ")}"
+	}
+	return "${" + name + "}"
+}
+
+// SanitizedForTemplateLiteral escapes the text for use in a template
+// literal.
+func SanitizedForTemplateLiteral(text string) string {
+	ret := ""
+	for i, c := range text {
+		switch c {
+		case '\\':
+			ret += "\\\\"
+		case '`':
+			ret += "\\`"
+		case '$':
+			if strings.HasPrefix(text[i+1:], "{") {
+				ret += "\\$"
+			} else {
+				ret += "$"
+			}
+		case '\n':
+			ret += "\\n"
+		case '\r':
+			ret += "\\r"
+		case '\t':
+			ret += "\\t"
+		default:
+			if c < 0x20 || c == 0x7f || c == 0x2028 || c == 0x2029 {
+				ret += fmt.Sprintf("\\u%04x", c)
+			} else {
+				ret += string(c)
+			}
+		}
+	}
+	return ret
+}
+
+func typeScriptTemplateLiteralFromSegments(segments []model.Segment) string {
+	ret := "`"
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += SanitizedForTemplateLiteral(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += TypeScriptFormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret + "`"
+}
+
+func typeScriptStringLiteral(text string) string {
+	return strings.TrimSuffix(util.IndentedJSON(text), "\n")
+}
+
+// typeScriptParameters returns the parameter list for the arguments.
+// Arguments that are not used are prefixed with an underscore (to keep
+// `noUnusedParameters` happy) if isImplementation is true.
+func typeScriptParameters(arguments []model.FormatSpecifierSegment, isImplementation bool) string {
+	parameters := make([]string, 0, len(arguments))
+	for index, argument := range arguments {
+		name := model.ArgumentName(index)
+		if isImplementation && argument.DataType == model.DataTypeNone {
+			name = "_" + name
+		}
+		parameters = append(parameters, name+": "+TypeScriptTypeForDataType(argument.DataType))
+	}
+	return "(" + strings.Join(parameters, ", ") + ")"
+}
+
+func typeScriptDocComment(paragraphs []string, indent string) string {
+	ret := indent + "/**\n"
+	for i, paragraph := range paragraphs {
+		if 0 < i {
+			ret += indent + " *\n"
+		}
+		for _, line := range strings.Split(strings.Replace(paragraph, "*/", "* /", -1), "\n") {
+			ret += strings.TrimRight(indent+" * "+line, " ") + "\n"
+		}
+	}
+	return ret + indent + " */\n"
+}
+
+func docTextFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += segment.(model.TextSegment).Text
+		case model.FormatSpecifierSegment:
+			ret += "{" + model.ArgumentName(segment.(model.FormatSpecifierSegment).ArgumentIndex(position)) + "}"
+			position++
+		}
+	}
+	return ret
+}
+
+// typeScriptTranslations returns the translations in the set that are
+// written as properties of the messages, along with warnings about the
+// translations that are skipped because their key is the same as that
+// of an earlier translation (in another section), which would be a
+// duplicate property.
+func typeScriptTranslations(set model.TranslationSet) ([]model.Translation, []string) {
+	ret := make([]model.Translation, 0)
+	warnings := make([]string, 0)
+	keysUsed := make(map[string]bool)
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformWeb) {
+				continue
+			}
+			if keysUsed[translation.Key] {
+				warnings = append(warnings, "Skipping translation '"+translation.Key+"' in section '"+section.Name+"': its key is the same as that of an earlier translation")
+				continue
+			}
+			keysUsed[translation.Key] = true
+			ret = append(ret, translation)
+		}
+	}
+	return ret, warnings
+}
+
+// GetTypeScriptDeclarationFileContents returns the `Messages` interface
+// declaration. The types of the arguments (and the documentation) come
+// from the first language that has a value for the translation.
+func GetTypeScriptDeclarationFileContents(set model.TranslationSet) string {
+	translations, _ := typeScriptTranslations(set)
+	return typeScriptDeclarationFileContents(set, translations)
+}
+
+func typeScriptDeclarationFileContents(set model.TranslationSet, translations []model.Translation) string {
+	ret := "// Generated by Sanat\n\nexport interface Messages {\n"
+	for _, translation := range translations {
+		var value *model.TranslationValue
+		for _, language := range set.Languages {
+			if value = translation.ValueForLanguage(language); value != nil {
+				break
+			}
+		}
+		if value == nil {
+			continue
+		}
+
+		docParagraphs := []string{docTextFromSegments(value.Segments)}
+		if 0 < len(translation.Comment) {
+			docParagraphs = append(docParagraphs, translation.Comment)
+		}
+		ret += typeScriptDocComment(docParagraphs, "  ")
+		ret += "  " + typeScriptStringLiteral(translation.Key) + ": " + typeScriptParameters(value.Arguments(), false) + " => string;\n"
+	}
+	return ret + "}\n"
+}
+
+func GetTypeScriptModuleFileContents(set model.TranslationSet, language string) string {
+	translations, _ := typeScriptTranslations(set)
+	return typeScriptModuleFileContents(translations, language)
+}
+
+func typeScriptModuleFileContents(translations []model.Translation, language string) string {
+	ret := "// Generated by Sanat\n\n" +
+		"import type { Messages } from \"./" + typeScriptModuleName + "\";\n\n" +
+		"const messages: Messages = {\n"
+	for _, translation := range translations {
+		value := translation.ValueForLanguage(language)
+		if value == nil {
+			continue
+		}
+		ret += "  " + typeScriptStringLiteral(translation.Key) + ": " +
+			typeScriptParameters(value.Arguments(), true) + " => " +
+			typeScriptTemplateLiteralFromSegments(value.Segments) + ",\n"
+	}
+	return ret + "};\n\nexport default messages;\n"
+}
+
+// GetTypeScriptFiles returns the contents of all the files that
+// WriteTypeScriptFiles writes, keyed by path relative to the output
+// directory.
+func GetTypeScriptFiles(set model.TranslationSet) map[string]string {
+	translations, warnings := typeScriptTranslations(set)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "WARNING: "+warning)
+	}
+	ret := make(map[string]string)
+	ret[typeScriptModuleName+".d.ts"] = typeScriptDeclarationFileContents(set, translations)
+	for _, language := range set.Languages {
+		ret[typeScriptModuleName+"."+language+".ts"] = typeScriptModuleFileContents(translations, language)
+	}
+	return ret
+}
+
+func WriteTypeScriptFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetTypeScriptFiles(set))
+}
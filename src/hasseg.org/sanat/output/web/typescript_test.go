@@ -0,0 +1,106 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/web"
+	"hasseg.org/sanat/test"
+)
+
+func TestTypeScriptFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return web.TypeScriptFormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "${arg1}", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "${arg2}", val(model.DataTypeInteger, 1, -1, -1), "")
+	assert.Equal(t, "${arg1}", val(model.DataTypeFloat, 0, -1, -1), "")
+	assert.Equal(t, "${arg1.toFixed(2)}", val(model.DataTypeFloat, 0, 2, -1), "")
+	assert.Equal(t, "${arg12}", val(model.DataTypeString, 2, -1, 12), "Explicit order index overrides actual position")
+}
+
+func TestTextSanitizedForTemplateLiteral(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, web.SanitizedForTemplateLiteral(input), input)
+	}
+
+	ass("", "")
+	ass("Costs $5", "Costs $5")
+	ass("\\${not} substituted", "${not} substituted")
+	ass("\\`quoted\\` \\\\ back", "`quoted` \\ back")
+	ass("line\\nbreak", "line\nbreak")
+}
+
+func TestTypeScriptFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	title := section.AddTranslation("LoginView.Title")
+	title.Comment = "Page title"
+	title.AddValue("en", []model.Segment{model.NewTextSegment("Log in")})
+	greeting := section.AddTranslation("LoginView.Greeting")
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+	})
+	greeting.AddValue("fi", []model.Segment{
+		model.NewTextSegment("Hei "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+	})
+	section.AddTranslation("FinnishOnly").AddValue("fi", []model.Segment{model.NewTextSegment("Moi")})
+	duplicate := ts.AddSection("Other").AddTranslation("LoginView.Title")
+	duplicate.AddValue("en", []model.Segment{model.NewTextSegment("Duplicate")})
+	duplicate.AddValue("fi", []model.Segment{model.NewTextSegment("Kaksoiskappale")})
+	ts.AddLanguage("en")
+	ts.AddLanguage("fi")
+
+	assert.Equal(t, `// Generated by Sanat
+
+export interface Messages {
+  /**
+   * Log in
+   *
+   * Page title
+   */
+  "LoginView.Title": () => string;
+  /**
+   * Hi {arg2}, you owe {arg1}
+   */
+  "LoginView.Greeting": (arg1: number, arg2: string) => string;
+  /**
+   * Moi
+   */
+  "FinnishOnly": () => string;
+}
+`, web.GetTypeScriptDeclarationFileContents(ts), "")
+
+	assert.Equal(t, `// Generated by Sanat
+
+import type { Messages } from "./messages";
+
+const messages: Messages = {
+  "LoginView.Greeting": (_arg1: unknown, arg2: string) => `+"`Hei ${arg2}`"+`,
+  "FinnishOnly": () => `+"`Moi`"+`,
+};
+
+export default messages;
+`, web.GetTypeScriptModuleFileContents(ts, "fi"), "")
+
+	files := web.GetTypeScriptFiles(ts)
+	assert.Equal(t, 3, len(files), "")
+	_, exists := files["messages.en.ts"]
+	assert.True(t, exists, "")
+}
+
+func TestTypeScriptComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	files := web.GetTypeScriptFiles(set)
+	assert.Equal(t, len(set.Languages)+1, len(files), "")
+}
@@ -0,0 +1,144 @@
+package web
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/icu"
+	"hasseg.org/sanat/util"
+)
+
+// I18nextFormatSpecifierStringForFormatSpecifier returns an i18next
+// interpolation for the format specifier. The arguments are named by
+// their 0-based index, so they're passed to `t()` like so:
+// `t('key', {0: 'foo', 1: 42})`.
+func I18nextFormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	ret := "{{" + strconv.Itoa(segment.ArgumentIndex(position))
+	if segment.DataType == model.DataTypeFloat && 0 <= segment.NumberOfDecimals {
+		decimals := strconv.Itoa(segment.NumberOfDecimals)
+		ret += ", number(minimumFractionDigits: " + decimals + "; maximumFractionDigits: " + decimals + ")"
+	}
+	return ret + "}}"
+}
+
+func i18nextStringFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += segment.(model.TextSegment).Text
+		case model.FormatSpecifierSegment:
+			ret += I18nextFormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+func getLocaleFileContents(set model.TranslationSet, language string, stringFromSegments func([]model.Segment) string, nested bool) string {
+	messages := util.NewOrderedMap()
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformWeb) {
+				continue
+			}
+
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+
+			keyPath := []string{translation.Key}
+			if nested {
+				keyPath = strings.Split(translation.Key, ".")
+			}
+			if err := messages.SetAtPath(keyPath, stringFromSegments(value.Segments)); err != nil {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"' ("+language+"):", err.Error())
+			}
+		}
+	}
+	return util.IndentedJSON(messages)
+}
+
+// GetI18nextLocaleFileContents returns the messages for the language as
+// an i18next JSON resource. If nested is true, dot-separated keys are
+// split into nested objects.
+func GetI18nextLocaleFileContents(set model.TranslationSet, language string, nested bool) string {
+	return getLocaleFileContents(set, language, i18nextStringFromSegments, nested)
+}
+
+// GetFormatJSLocaleFileContents returns the messages for the language as
+// a JSON object of ICU MessageFormat strings. If nested is true,
+// dot-separated keys are split into nested objects.
+func GetFormatJSLocaleFileContents(set model.TranslationSet, language string, nested bool) string {
+	return getLocaleFileContents(set, language, icu.MessageFromSegments, nested)
+}
+
+func localeFilePath(language string) string {
+	return path.Join("locales", language+".json")
+}
+
+// GetI18nextFiles returns the contents of all the files that
+// WriteI18nextFiles writes, keyed by path relative to the output
+// directory.
+func GetI18nextFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[localeFilePath(language)] = GetI18nextLocaleFileContents(set, language, false)
+	}
+	return ret
+}
+
+// GetNestedI18nextFiles returns the contents of all the files that
+// WriteNestedI18nextFiles writes, keyed by path relative to the output
+// directory.
+func GetNestedI18nextFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[localeFilePath(language)] = GetI18nextLocaleFileContents(set, language, true)
+	}
+	return ret
+}
+
+// GetFormatJSFiles returns the contents of all the files that
+// WriteFormatJSFiles writes, keyed by path relative to the output
+// directory.
+func GetFormatJSFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[localeFilePath(language)] = GetFormatJSLocaleFileContents(set, language, false)
+	}
+	return ret
+}
+
+// GetNestedFormatJSFiles returns the contents of all the files that
+// WriteNestedFormatJSFiles writes, keyed by path relative to the output
+// directory.
+func GetNestedFormatJSFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[localeFilePath(language)] = GetFormatJSLocaleFileContents(set, language, true)
+	}
+	return ret
+}
+
+func WriteI18nextFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetI18nextFiles(set))
+}
+
+func WriteNestedI18nextFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetNestedI18nextFiles(set))
+}
+
+func WriteFormatJSFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetFormatJSFiles(set))
+}
+
+func WriteNestedFormatJSFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetNestedFormatJSFiles(set))
+}
@@ -0,0 +1,92 @@
+package web_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/web"
+	"hasseg.org/sanat/test"
+)
+
+func TestI18nextFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return web.I18nextFormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	// Data types
+	assert.Equal(t, "{{0}}", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "{{0}}", val(model.DataTypeString, 0, -1, -1), "")
+	assert.Equal(t, "{{0}}", val(model.DataTypeFloat, 0, -1, -1), "")
+	assert.Equal(t, "{{0}}", val(model.DataTypeInteger, 0, -1, -1), "")
+
+	// Semantic order index
+	assert.Equal(t, "{{2}}", val(model.DataTypeObject, 2, -1, -1), "")
+	assert.Equal(t, "{{0}}", val(model.DataTypeObject, 2, -1, 1), "Output index is 0-based while explicit order index is 1-based")
+
+	// Decimal count
+	assert.Equal(t, "{{0, number(minimumFractionDigits: 2; maximumFractionDigits: 2)}}", val(model.DataTypeFloat, 0, 2, -1), "")
+	assert.Equal(t, "{{0}}", val(model.DataTypeInteger, 0, 2, -1), "Decimal count is only for floats")
+}
+
+func makeTranslationSet(keys []string, language string) model.TranslationSet {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	for _, key := range keys {
+		section.AddTranslation(key).AddValue(language, []model.Segment{
+			model.NewTextSegment("Hi "),
+			model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		})
+	}
+	ts.AddLanguage(language)
+	return ts
+}
+
+func TestLocaleFileNesting(t *testing.T) {
+	ts := makeTranslationSet([]string{"LoginView.Title", "LoginView.Button", "Other"}, "en")
+
+	assert.Equal(t, "{\n"+
+		"  \"LoginView.Title\": \"Hi {{0}}\",\n"+
+		"  \"LoginView.Button\": \"Hi {{0}}\",\n"+
+		"  \"Other\": \"Hi {{0}}\"\n"+
+		"}\n", web.GetI18nextLocaleFileContents(ts, "en", false), "")
+
+	assert.Equal(t, "{\n"+
+		"  \"LoginView\": {\n"+
+		"    \"Title\": \"Hi {0}\",\n"+
+		"    \"Button\": \"Hi {0}\"\n"+
+		"  },\n"+
+		"  \"Other\": \"Hi {0}\"\n"+
+		"}\n", web.GetFormatJSLocaleFileContents(ts, "en", true), "")
+
+	// Conflicting keys are skipped
+	ts = makeTranslationSet([]string{"Foo", "Foo.Bar"}, "en")
+	assert.Equal(t, "{\n  \"Foo\": \"Hi {{0}}\"\n}\n", web.GetI18nextLocaleFileContents(ts, "en", true), "")
+}
+
+func TestWebPlatformLimit(t *testing.T) {
+	ts := makeTranslationSet([]string{"Foo", "Bar"}, "en")
+	ts.Sections[0].Translations[1].Platforms = []model.TranslationPlatform{model.PlatformAndroid}
+	assert.Equal(t, "{\n  \"Foo\": \"Hi {{0}}\"\n}\n", web.GetI18nextLocaleFileContents(ts, "en", false), "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	for _, files := range []map[string]string{
+		web.GetI18nextFiles(set),
+		web.GetNestedI18nextFiles(set),
+		web.GetFormatJSFiles(set),
+		web.GetNestedFormatJSFiles(set),
+	} {
+		assert.Equal(t, len(set.Languages), len(files), "")
+		for filePath, contents := range files {
+			var messages map[string]interface{}
+			assert.Nil(t, json.Unmarshal([]byte(contents), &messages), filePath)
+		}
+	}
+}
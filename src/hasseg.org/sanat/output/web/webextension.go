@@ -0,0 +1,163 @@
+package web
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// WebExtension (Chrome, Firefox etc.) messages refer to named
+// placeholders (`$arg1$`), each of which is defined in the message's
+// `placeholders` as one of the substitutions passed to
+// `i18n.getMessage()` (`$1`.) Only nine substitutions are supported.
+const webExtensionMaxSubstitutions = 9
+
+// WebExtensionFormatSpecifierStringForFormatSpecifier returns the
+// placeholder reference for the format specifier. The substitutions are
+// always strings, so the number of decimals is ignored.
+func WebExtensionFormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	return "$" + model.ArgumentName(segment.ArgumentIndex(position)) + "$"
+}
+
+// SanitizedForWebExtensionMessage escapes dollar signs, which would
+// otherwise be read as the start of a placeholder reference.
+func SanitizedForWebExtensionMessage(text string) string {
+	return strings.Replace(text, "$", "$$", -1)
+}
+
+var invalidWebExtensionKeyCharsRegexp = regexp.MustCompile("[^A-Za-z0-9_]")
+
+// SanitizedForWebExtensionKey replaces the characters that are not
+// allowed in message names with underscores.
+func SanitizedForWebExtensionKey(key string) string {
+	return invalidWebExtensionKeyCharsRegexp.ReplaceAllString(key, "_")
+}
+
+func webExtensionStringFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += SanitizedForWebExtensionMessage(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += WebExtensionFormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+// WebExtensionLocaleForLanguage turns a BCP 47 language identifier into
+// the underscore-separated form used in `_locales` directory names.
+func WebExtensionLocaleForLanguage(language string) string {
+	return strings.Replace(language, "-", "_", -1)
+}
+
+// webExtensionKeys returns the message names of the translations in the
+// set, indexed like set.Sections[i].Translations[j], along with warnings
+// about the translations that are skipped (and get an empty name)
+// because their message name is the same as that of an earlier
+// translation. The names don't depend on the language, so that the same
+// name refers to the same translation in all of the locales.
+func webExtensionKeys(set model.TranslationSet) ([][]string, []string) {
+	keys := make([][]string, len(set.Sections))
+	warnings := make([]string, 0)
+
+	// Message names are case-insensitive
+	keysByLowercaseSanitizedKey := make(map[string]string)
+
+	for sectionIndex, section := range set.Sections {
+		keys[sectionIndex] = make([]string, len(section.Translations))
+		for translationIndex, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformWeb) {
+				continue
+			}
+			key := SanitizedForWebExtensionKey(translation.Key)
+			if originalKey, exists := keysByLowercaseSanitizedKey[strings.ToLower(key)]; exists {
+				warnings = append(warnings, "Skipping translation '"+translation.Key+"': its message name '"+key+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			keysByLowercaseSanitizedKey[strings.ToLower(key)] = translation.Key
+			keys[sectionIndex][translationIndex] = key
+		}
+	}
+	return keys, warnings
+}
+
+func GetWebExtensionMessagesFileContents(set model.TranslationSet, language string) string {
+	keys, _ := webExtensionKeys(set)
+	return webExtensionMessagesFileContents(set, language, keys)
+}
+
+func webExtensionMessagesFileContents(set model.TranslationSet, language string, keys [][]string) string {
+	messages := util.NewOrderedMap()
+	for sectionIndex, section := range set.Sections {
+		for translationIndex, translation := range section.Translations {
+			key := keys[sectionIndex][translationIndex]
+			if len(key) == 0 {
+				continue
+			}
+
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+
+			// Browsers don't substitute $10 and above, so the message
+			// would show them as is
+			arguments := value.Arguments()
+			if webExtensionMaxSubstitutions < len(arguments) {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"' ("+language+"): it has "+strconv.Itoa(len(arguments))+" arguments but only "+strconv.Itoa(webExtensionMaxSubstitutions)+" are supported")
+				continue
+			}
+
+			message := util.NewOrderedMap()
+			message.Set("message", webExtensionStringFromSegments(value.Segments))
+			if 0 < len(translation.Comment) {
+				message.Set("description", translation.Comment)
+			}
+
+			placeholders := util.NewOrderedMap()
+			for index, argument := range arguments {
+				if argument.DataType == model.DataTypeNone {
+					continue
+				}
+				placeholder := util.NewOrderedMap()
+				placeholder.Set("content", "$"+strconv.Itoa(index+1))
+				placeholders.Set(model.ArgumentName(index), placeholder)
+			}
+			if 0 < len(placeholders.Keys) {
+				message.Set("placeholders", placeholders)
+			}
+
+			messages.Set(key, message)
+		}
+	}
+	return util.IndentedJSON(messages)
+}
+
+// GetWebExtensionFiles returns the contents of all the files that
+// WriteWebExtensionFiles writes, keyed by path relative to the output
+// directory.
+func GetWebExtensionFiles(set model.TranslationSet) map[string]string {
+	keys, warnings := webExtensionKeys(set)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "WARNING: "+warning)
+	}
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[path.Join("_locales", WebExtensionLocaleForLanguage(language), "messages.json")] = webExtensionMessagesFileContents(set, language, keys)
+	}
+	return ret
+}
+
+func WriteWebExtensionFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetWebExtensionFiles(set))
+}
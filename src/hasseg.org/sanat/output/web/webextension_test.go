@@ -0,0 +1,88 @@
+package web_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/web"
+	"hasseg.org/sanat/test"
+)
+
+func TestWebExtensionFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return web.WebExtensionFormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "$arg1$", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "$arg1$", val(model.DataTypeFloat, 0, 2, -1), "Substitutions are strings; decimal count is ignored")
+	assert.Equal(t, "$arg3$", val(model.DataTypeInteger, 2, -1, -1), "")
+	assert.Equal(t, "$arg2$", val(model.DataTypeString, 2, -1, 2), "Explicit order index overrides actual position")
+}
+
+func TestTextSanitizedForWebExtension(t *testing.T) {
+	assert.Equal(t, "Costs $$5", web.SanitizedForWebExtensionMessage("Costs $5"), "")
+	assert.Equal(t, "LoginView_Title", web.SanitizedForWebExtensionKey("LoginView.Title"), "")
+	assert.Equal(t, "a_b_c_d", web.SanitizedForWebExtensionKey("a b-cäd"), "")
+	assert.Equal(t, "Foo_123", web.SanitizedForWebExtensionKey("Foo_123"), "")
+}
+
+func TestWebExtensionMessagesFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	greeting := section.AddTranslation("Login.Greeting")
+	greeting.Comment = "Shown after logging in"
+	greeting.AddValue("en-US", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe $"),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+	})
+	collides := section.AddTranslation("Login_greeting")
+	collides.AddValue("en-US", []model.Segment{model.NewTextSegment("Collides")})
+	collides.AddValue("fi", []model.Segment{model.NewTextSegment("Törmää")})
+	section.AddTranslation("Plain").AddValue("en-US", []model.Segment{model.NewTextSegment("Plain")})
+	tooManyArguments := make([]model.Segment, 0)
+	for i := 0; i < 10; i++ {
+		tooManyArguments = append(tooManyArguments, model.NewFormatSpecifierSegment(model.DataTypeObject, -1, -1))
+	}
+	section.AddTranslation("TooManyArguments").AddValue("en-US", tooManyArguments)
+	ts.AddLanguage("en-US")
+	ts.AddLanguage("fi")
+
+	assert.Equal(t, `{
+  "Login_Greeting": {
+    "message": "Hi $arg2$, you owe $$$arg1$",
+    "description": "Shown after logging in",
+    "placeholders": {
+      "arg1": {
+        "content": "$1"
+      },
+      "arg2": {
+        "content": "$2"
+      }
+    }
+  },
+  "Plain": {
+    "message": "Plain"
+  }
+}
+`, web.GetWebExtensionMessagesFileContents(ts, "en-US"), "")
+	assert.Equal(t, "{}\n", web.GetWebExtensionMessagesFileContents(ts, "fi"), "Names that collide in any language are skipped in all of them")
+
+	_, exists := web.GetWebExtensionFiles(ts)["_locales/en_US/messages.json"]
+	assert.True(t, exists, "")
+}
+
+func TestWebExtensionComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	for filePath, contents := range web.GetWebExtensionFiles(set) {
+		var messages map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(contents), &messages), filePath)
+	}
+}
@@ -0,0 +1,229 @@
+package windows
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// The generated C# class looks up the strings with a ResourceManager
+// (by default one for the "AppResources" resources of the assembly
+// containing the class, as written by the windows-resx-satellite
+// format) or, for UWP apps, a ResourceLoader (by default the one for the
+// "Resources" files written by the windows-resw format), and has a
+// property for each translation, or a method if the translation has
+// format specifiers. The argument types and documentation come from the
+// first language of the set.
+
+// CSharpNamespace is the namespace of the generated C# class.
+var CSharpNamespace = ""
+
+const CSharpClassName = "Strings"
+
+// Names that are already taken in the generated class
+var reservedCSharpMemberNames = map[string]bool{
+	CSharpClassName:   true,
+	"ResourceManager": true,
+	"ResourceLoader":  true,
+	"Culture":         true,
+	"GetString":       true,
+}
+
+// CSharpTypeForDataType returns the C# type of the arguments of format
+// specifiers with the given data type.
+func CSharpTypeForDataType(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "string"
+	case model.DataTypeInteger:
+		return "int"
+	case model.DataTypeFloat:
+		return "double"
+	}
+	return "object"
+}
+
+// CSharpStringLiteral returns the text as a (regular) C# string literal.
+func CSharpStringLiteral(text string) string {
+	ret := "\""
+	for _, c := range text {
+		switch c {
+		case '\\':
+			ret += "\\\\"
+		case '"':
+			ret += "\\\""
+		case '\n':
+			ret += "\\n"
+		case '\r':
+			ret += "\\r"
+		case '\t':
+			ret += "\\t"
+		case 0:
+			ret += "\\0"
+		default:
+			if c < 0x20 || c == 0x7f || c == 0x85 || c == 0x2028 || c == 0x2029 {
+				ret += fmt.Sprintf("\\u%04x", c)
+			} else {
+				ret += string(c)
+			}
+		}
+	}
+	return ret + "\""
+}
+
+func csharpDocComment(tag string, text string, indent string) string {
+	lines := strings.Split(util.XMLEscaped(text), "\n")
+	if len(lines) == 1 {
+		return indent + "/// <" + tag + ">" + lines[0] + "</" + tag + ">\n"
+	}
+	ret := indent + "/// <" + tag + ">\n"
+	for _, line := range lines {
+		ret += strings.TrimRight(indent+"/// "+line, " ") + "\n"
+	}
+	return ret + indent + "/// </" + tag + ">\n"
+}
+
+func csharpMember(translation model.Translation, sourceLanguage string, name string, indent string) string {
+	ret := ""
+	value := translation.ValueForLanguage(sourceLanguage)
+	var arguments []model.FormatSpecifierSegment
+	if value != nil {
+		ret += csharpDocComment("summary", formatStringFromSegments(value.Segments), indent)
+		arguments = value.Arguments()
+	}
+	if 0 < len(translation.Comment) {
+		ret += csharpDocComment("remarks", translation.Comment, indent)
+	}
+
+	lookup := "GetString(" + CSharpStringLiteral(ResourceName(translation.Key)) + ")"
+	if len(arguments) == 0 {
+		ret += indent + "public static string " + name + " => " + lookup + ";\n"
+		return ret
+	}
+
+	parameters := make([]string, 0, len(arguments))
+	argumentNames := make([]string, 0, len(arguments))
+	for index, argument := range arguments {
+		argumentName := model.ArgumentName(index)
+		parameters = append(parameters, CSharpTypeForDataType(argument.DataType)+" "+argumentName)
+		argumentNames = append(argumentNames, argumentName)
+	}
+	ret += indent + "public static string " + name + "(" + strings.Join(parameters, ", ") + ")\n"
+	ret += indent + "{\n"
+	ret += indent + "    return string.Format(Culture, " + lookup + ", " + strings.Join(argumentNames, ", ") + ");\n"
+	ret += indent + "}\n"
+	return ret
+}
+
+// GetCSharpFileContents returns C# source code for a static class with
+// typed accessors for the translations, which looks the strings up with
+// a ResourceManager.
+func GetCSharpFileContents(set model.TranslationSet) string {
+	return csharpFileContents(set, false)
+}
+
+// GetCSharpResourceLoaderFileContents returns C# source code for a
+// static class with typed accessors for the translations, which looks
+// the strings up with a UWP ResourceLoader.
+func GetCSharpResourceLoaderFileContents(set model.TranslationSet) string {
+	return csharpFileContents(set, true)
+}
+
+func csharpFileContents(set model.TranslationSet, useResourceLoader bool) string {
+	sourceLanguage := ""
+	if 0 < len(set.Languages) {
+		sourceLanguage = set.Languages[0]
+	}
+
+	indent := ""
+	ret := "// <auto-generated>\n// Generated by Sanat\n// </auto-generated>\n\n" +
+		"using System.Globalization;\n"
+	if useResourceLoader {
+		ret += "using Windows.ApplicationModel.Resources;\n\n"
+	} else {
+		ret += "using System.Resources;\n\n"
+	}
+	baseName := "AppResources"
+	if 0 < len(CSharpNamespace) {
+		ret += "namespace " + CSharpNamespace + "\n{\n"
+		indent = "    "
+		baseName = CSharpNamespace + "." + baseName
+	}
+
+	ret += indent + "public static partial class " + CSharpClassName + "\n" + indent + "{\n"
+	if useResourceLoader {
+		ret += indent + "    public static ResourceLoader ResourceLoader { get; set; } =\n"
+		ret += indent + "        ResourceLoader.GetForViewIndependentUse();\n\n"
+		ret += indent + "    /// <summary>The culture to format the arguments for; the current UI culture if null.</summary>\n"
+		ret += indent + "    public static CultureInfo Culture { get; set; }\n\n"
+		ret += indent + "    private static string GetString(string name)\n"
+		ret += indent + "    {\n"
+		ret += indent + "        return ResourceLoader.GetString(name);\n"
+		ret += indent + "    }\n"
+	} else {
+		ret += indent + "    public static ResourceManager ResourceManager { get; set; } =\n"
+		ret += indent + "        new ResourceManager(" + CSharpStringLiteral(baseName) + ", typeof(" + CSharpClassName + ").Assembly);\n\n"
+		ret += indent + "    /// <summary>The culture to look up the strings (and format the arguments) for; the current UI culture if null.</summary>\n"
+		ret += indent + "    public static CultureInfo Culture { get; set; }\n\n"
+		ret += indent + "    private static string GetString(string name)\n"
+		ret += indent + "    {\n"
+		ret += indent + "        return ResourceManager.GetString(name, Culture);\n"
+		ret += indent + "    }\n"
+	}
+
+	namesUsed := make(map[string]string)
+	for _, section := range set.Sections {
+		sectionHeadingPrinted := false
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformWindows) {
+				continue
+			}
+
+			name := util.CamelCaseIdentifier(translation.Key, true)
+			if reservedCSharpMemberNames[name] {
+				name += "_"
+			}
+			if originalKey, exists := namesUsed[name]; exists {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"': its C# name '"+name+"' is the same as that of '"+originalKey+"'")
+				continue
+			}
+			namesUsed[name] = translation.Key
+
+			if !sectionHeadingPrinted && 0 < len(section.Name) {
+				ret += "\n" + indent + "    // ********** " + strings.Replace(section.Name, "\n", " ", -1) + " **********\n"
+				sectionHeadingPrinted = true
+			}
+			ret += "\n" + csharpMember(translation, sourceLanguage, name, indent+"    ")
+		}
+	}
+
+	ret += indent + "}\n"
+	if 0 < len(CSharpNamespace) {
+		ret += "}\n"
+	}
+	return ret
+}
+
+// GetCSharpFiles returns the contents of the file that WriteCSharpFile
+// writes, keyed by path relative to the output directory.
+func GetCSharpFiles(set model.TranslationSet) map[string]string {
+	return map[string]string{CSharpClassName + ".cs": GetCSharpFileContents(set)}
+}
+
+func WriteCSharpFile(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetCSharpFiles(set))
+}
+
+// GetCSharpResourceLoaderFiles returns the contents of the file that
+// WriteCSharpResourceLoaderFile writes, keyed by path relative to the
+// output directory.
+func GetCSharpResourceLoaderFiles(set model.TranslationSet) map[string]string {
+	return map[string]string{CSharpClassName + ".cs": GetCSharpResourceLoaderFileContents(set)}
+}
+
+func WriteCSharpResourceLoaderFile(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetCSharpResourceLoaderFiles(set))
+}
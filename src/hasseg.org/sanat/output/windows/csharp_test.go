@@ -0,0 +1,129 @@
+package windows_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/windows"
+	"hasseg.org/sanat/test"
+)
+
+func TestCSharpStringLiteral(t *testing.T) {
+	assert.Equal(t, `""`, windows.CSharpStringLiteral(""), "")
+	assert.Equal(t, `"Say \"hi\" \\ \n\t\u0007"`, windows.CSharpStringLiteral("Say \"hi\" \\ \n\t\a"), "")
+}
+
+func TestCSharpFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("Login")
+	title := section.AddTranslation("LoginView.Title")
+	title.Comment = "Title <bar>"
+	title.AddValue("en", []model.Segment{model.NewTextSegment("Log in")})
+	greeting := section.AddTranslation("LoginView.Greeting")
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe {"),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+		model.NewTextSegment("} for "),
+		model.NewFormatSpecifierSegment(model.DataTypeInteger, -1, 3),
+		model.NewTextSegment(" "),
+		model.NewFormatSpecifierSegment(model.DataTypeObject, -1, 4),
+	})
+	section.AddTranslation("LoginView title").AddValue("en", []model.Segment{model.NewTextSegment("Collides")})
+	section.AddTranslation("Culture").AddValue("en", []model.Segment{model.NewTextSegment("Culture")})
+	android := section.AddTranslation("AndroidOnly")
+	android.Platforms = []model.TranslationPlatform{model.PlatformAndroid}
+	android.AddValue("en", []model.Segment{model.NewTextSegment("Droid")})
+	ts.AddLanguage("en")
+
+	windows.CSharpNamespace = "Example.App"
+	defer func() { windows.CSharpNamespace = "" }()
+
+	assert.Equal(t, `// <auto-generated>
+// Generated by Sanat
+// </auto-generated>
+
+using System.Globalization;
+using System.Resources;
+
+namespace Example.App
+{
+    public static partial class Strings
+    {
+        public static ResourceManager ResourceManager { get; set; } =
+            new ResourceManager("Example.App.AppResources", typeof(Strings).Assembly);
+
+        /// <summary>The culture to look up the strings (and format the arguments) for; the current UI culture if null.</summary>
+        public static CultureInfo Culture { get; set; }
+
+        private static string GetString(string name)
+        {
+            return ResourceManager.GetString(name, Culture);
+        }
+
+        // ********** Login **********
+
+        /// <summary>Log in</summary>
+        /// <remarks>Title &lt;bar&gt;</remarks>
+        public static string LoginViewTitle => GetString("LoginView_Title");
+
+        /// <summary>Hi {1}, you owe {{{0:F2}}} for {2} {3}</summary>
+        public static string LoginViewGreeting(double arg1, string arg2, int arg3, object arg4)
+        {
+            return string.Format(Culture, GetString("LoginView_Greeting"), arg1, arg2, arg3, arg4);
+        }
+
+        /// <summary>Culture</summary>
+        public static string Culture_ => GetString("Culture");
+    }
+}
+`, windows.GetCSharpFileContents(ts), "")
+}
+
+func TestCSharpResourceLoaderFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	greeting := ts.AddSection("").AddTranslation("Greeting")
+	greeting.AddValue("en", []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+	})
+	ts.AddLanguage("en")
+
+	assert.Equal(t, `// <auto-generated>
+// Generated by Sanat
+// </auto-generated>
+
+using System.Globalization;
+using Windows.ApplicationModel.Resources;
+
+public static partial class Strings
+{
+    public static ResourceLoader ResourceLoader { get; set; } =
+        ResourceLoader.GetForViewIndependentUse();
+
+    /// <summary>The culture to format the arguments for; the current UI culture if null.</summary>
+    public static CultureInfo Culture { get; set; }
+
+    private static string GetString(string name)
+    {
+        return ResourceLoader.GetString(name);
+    }
+
+    /// <summary>Hi {0}</summary>
+    public static string Greeting(string arg1)
+    {
+        return string.Format(Culture, GetString("Greeting"), arg1);
+    }
+}
+`, windows.GetCSharpResourceLoaderFileContents(ts), "")
+}
+
+func TestCSharpComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	contents := windows.GetCSharpFiles(set)["Strings.cs"]
+	assert.True(t, strings.HasPrefix(contents, "// <auto-generated>\n"), "")
+}
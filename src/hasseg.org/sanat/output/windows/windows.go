@@ -2,7 +2,6 @@ package windows
 
 import (
 	"fmt"
-	"os"
 	"path"
 	"regexp"
 	"strconv"
@@ -89,31 +88,56 @@ func SanitizedForStringValue(text string) string {
 	return util.XMLEscaped(text)
 }
 
-func SanitizedForKey(text string) string {
+// ResourceName turns the translation key into the name of the resource.
+func ResourceName(text string) string {
 	// Visual Studio generates C# code from resx (and sometimes resw) format
 	// resource XML files, which means that the keys must be valid C#
 	// identifiers. Let's automatically fix some common issues related to that.
 	//
-	invalidCharsRegexp, err := regexp.Compile("[. ]")
-	if err != nil {
-		panic(err)
-	}
-	return util.XMLEscaped(invalidCharsRegexp.ReplaceAllString(text, "_"))
+	return invalidKeyCharsRegexp.ReplaceAllString(text, "_")
+}
+
+var invalidKeyCharsRegexp = regexp.MustCompile("[. ]")
+
+func SanitizedForKey(text string) string {
+	return util.XMLEscaped(ResourceName(text))
 }
 
-func stringFromSegments(segments []model.Segment) string {
+// formatStringFromSegments returns the composite format string (as
+// used with `string.Format()`) for the segments. Braces are only doubled
+// in values that have format specifiers, since values without them may
+// be used as-is.
+func formatStringFromSegments(segments []model.Segment) string {
+	hasFormatSpecifiers := false
+	for _, segment := range segments {
+		if _, isFormatSpecifier := segment.(model.FormatSpecifierSegment); isFormatSpecifier {
+			hasFormatSpecifiers = true
+			break
+		}
+	}
+
 	ret := ""
-	for index, segment := range segments {
+	position := 0
+	for _, segment := range segments {
 		switch segment.(type) {
 		case model.TextSegment:
-			ret += SanitizedForStringValue(segment.(model.TextSegment).Text)
+			text := segment.(model.TextSegment).Text
+			if hasFormatSpecifiers {
+				text = strings.Replace(strings.Replace(text, "{", "{{", -1), "}", "}}", -1)
+			}
+			ret += text
 		case model.FormatSpecifierSegment:
-			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), index)
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
 		}
 	}
 	return ret
 }
 
+func StringFromSegments(segments []model.Segment) string {
+	return SanitizedForStringValue(formatStringFromSegments(segments))
+}
+
 func GetStringsFileContents(set model.TranslationSet, language string) string {
 	ret := "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n" +
 		"<!--\n" +
@@ -143,7 +167,7 @@ func GetStringsFileContents(set model.TranslationSet, language string) string {
 			}
 
 			ret += fmt.Sprintf("  <data name=\"%s\" xml:space=\"preserve\">\n", SanitizedForKey(translation.Key))
-			ret += fmt.Sprintf("    <value>%s</value>\n", stringFromSegments(value.Segments))
+			ret += fmt.Sprintf("    <value>%s</value>\n", StringFromSegments(value.Segments))
 			if 0 < len(translation.Comment) {
 				ret += fmt.Sprintf("    <comment>%s</comment>\n", util.XMLEscaped(translation.Comment))
 			}
@@ -154,35 +178,55 @@ func GetStringsFileContents(set model.TranslationSet, language string) string {
 	return ret
 }
 
-func WriteResxStringsFiles(set model.TranslationSet, outDirPath string) {
-	for language, _ := range set.Languages {
-		os.MkdirAll(outDirPath, 0777)
+// GetResxStringsFiles returns the contents of all the files that
+// WriteResxStringsFiles writes, keyed by path relative to the output
+// directory.
+func GetResxStringsFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret["AppResources-"+language+".resx"] = GetStringsFileContents(set, language)
+	}
+	return ret
+}
 
-		f, err := os.Create(path.Join(outDirPath, "AppResources-"+language+".resx"))
-		if err != nil {
-			panic(err)
+// GetSatelliteResxStringsFiles returns the contents of all the files
+// that WriteSatelliteResxStringsFiles writes, keyed by path relative to
+// the output directory. The files are named the way MSBuild names the
+// resources of a single ResourceManager: `AppResources.resx` has the
+// neutral resources (in the first language), and the
+// `AppResources.<language>.resx` files of the other languages are
+// compiled into satellite assemblies.
+func GetSatelliteResxStringsFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for index, language := range set.Languages {
+		if index == 0 {
+			ret["AppResources.resx"] = GetStringsFileContents(set, language)
+		} else {
+			ret["AppResources."+language+".resx"] = GetStringsFileContents(set, language)
 		}
+	}
+	return ret
+}
 
-		_, err = f.WriteString(GetStringsFileContents(set, language))
-		if err != nil {
-			panic(err)
-		}
+// GetReswStringsFiles returns the contents of all the files that
+// WriteReswStringsFiles writes, keyed by path relative to the output
+// directory.
+func GetReswStringsFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[path.Join(language, "Resources.resw")] = GetStringsFileContents(set, language)
 	}
+	return ret
 }
 
-func WriteReswStringsFiles(set model.TranslationSet, outDirPath string) {
-	for language, _ := range set.Languages {
-		langDirPath := path.Join(outDirPath, language)
-		os.MkdirAll(langDirPath, 0777)
+func WriteResxStringsFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetResxStringsFiles(set))
+}
 
-		f, err := os.Create(path.Join(langDirPath, "Resources.resw"))
-		if err != nil {
-			panic(err)
-		}
+func WriteSatelliteResxStringsFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetSatelliteResxStringsFiles(set))
+}
 
-		_, err = f.WriteString(GetStringsFileContents(set, language))
-		if err != nil {
-			panic(err)
-		}
-	}
+func WriteReswStringsFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetReswStringsFiles(set))
 }
@@ -92,9 +92,36 @@ func TestOverallXMLFileGeneration(t *testing.T) {
 	}
 }
 
+func TestFormatSpecifierIndexesInXMLFile(t *testing.T) {
+	lang := "en"
+	ts := model.NewTranslationSet()
+	ts.AddSection("").AddTranslation("Foo").AddValue(lang, []model.Segment{
+		model.NewTextSegment("{"),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment("} and "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 1, -1),
+	})
+	ts.AddSection("").AddTranslation("Bar").AddValue(lang, []model.Segment{model.NewTextSegment("{Bar}")})
+	x := windows.GetStringsFileContents(ts, lang)
+	assert.Contains(t, x, "<value>{{{0}}} and {1:F1}</value>", "Arguments are numbered by position among the format specifiers")
+	assert.Contains(t, x, "<value>{Bar}</value>", "Braces are only doubled in format strings")
+}
+
+func TestSatelliteResxFileNames(t *testing.T) {
+	ts := makeTranslationSet("", "Foo", "en", "Some text")
+	ts.AddLanguage("en")
+	ts.AddLanguage("fi-FI")
+	files := windows.GetSatelliteResxStringsFiles(ts)
+	assert.Equal(t, 2, len(files), "")
+	_, exists := files["AppResources.resx"]
+	assert.True(t, exists, "The first language is the neutral resources")
+	_, exists = files["AppResources.fi-FI.resx"]
+	assert.True(t, exists, "")
+}
+
 func TestComprehensiveInput(t *testing.T) {
 	set := test.GetComprehensiveTestInputTranslationSet()
-	for language, _ := range set.Languages {
+	for _, language := range set.Languages {
 		output := windows.GetStringsFileContents(set, language)
 		assert.True(t, util.XMLIsValid(output), language)
 	}
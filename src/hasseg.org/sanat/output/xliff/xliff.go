@@ -0,0 +1,155 @@
+package xliff
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// XLIFF 1.2 files contain the source text along with the translation,
+// so one language of the set (the first one) is used as the source
+// language. The files aren't for any particular platform, so format
+// specifiers are written as `<x/>` placeholders that show the argument
+// index (e.g. `{0}`) to the translators.
+//
+// http://docs.oasis-open.org/xliff/v1.2/os/xliff-core.html
+
+// FormatSpecifierStringForFormatSpecifier returns the `<x/>` placeholder
+// for the format specifier at the given (0-based) position among the
+// format specifiers in its value.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	return fmt.Sprintf("<x id=\"%d\" equiv-text=\"{%d}\"/>", position+1, segment.ArgumentIndex(position))
+}
+
+func stringFromSegments(segments []model.Segment) string {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += util.XMLEscaped(segment.(model.TextSegment).Text)
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+// transUnitIDs returns the `<trans-unit>` IDs (i.e. the keys) of the
+// translations in the set, indexed like set.Sections[i].Translations[j],
+// along with warnings about the translations that are skipped (and get
+// an empty ID) because their key is the same as that of a translation in
+// an earlier section; the IDs have to be unique within the file.
+func transUnitIDs(set model.TranslationSet) ([][]string, []string) {
+	ids := make([][]string, len(set.Sections))
+	warnings := make([]string, 0)
+	idsUsed := make(map[string]bool)
+	for sectionIndex, section := range set.Sections {
+		ids[sectionIndex] = make([]string, len(section.Translations))
+		for translationIndex, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			if idsUsed[translation.Key] {
+				warnings = append(warnings, "Skipping translation '"+translation.Key+"' in section '"+section.Name+"': its key is the same as that of an earlier translation")
+				continue
+			}
+			idsUsed[translation.Key] = true
+			ids[sectionIndex][translationIndex] = translation.Key
+		}
+	}
+	return ids, warnings
+}
+
+func transUnitString(translation model.Translation, sourceLanguage string, language string, indentation string) string {
+	ret := indentation + "<trans-unit id=\"" + util.XMLEscaped(translation.Key) + "\""
+	if maxLength := translation.MaxLength; 0 < maxLength {
+		ret += " maxwidth=\"" + strconv.Itoa(maxLength) + "\" size-unit=\"char\""
+	}
+	ret += ">\n"
+
+	source := ""
+	if sourceValue := translation.ValueForLanguage(sourceLanguage); sourceValue != nil {
+		source = stringFromSegments(sourceValue.Segments)
+	}
+	ret += indentation + "  <source>" + source + "</source>\n"
+
+	if value := translation.ValueForLanguage(language); value != nil {
+		ret += indentation + "  <target>" + stringFromSegments(value.Segments) + "</target>\n"
+	}
+	if 0 < len(translation.Comment) {
+		ret += indentation + "  <note>" + util.XMLEscaped(translation.Comment) + "</note>\n"
+	}
+	ret += indentation + "</trans-unit>\n"
+	return ret
+}
+
+// GetXLIFFFileContents returns the contents of an XLIFF 1.2 file with
+// the given language as the target language. Each named section becomes
+// a `<group>`, and the maximum lengths of the translations are written
+// as `maxwidth` attributes.
+func GetXLIFFFileContents(set model.TranslationSet, language string) string {
+	ids, _ := transUnitIDs(set)
+	return xliffFileContents(set, language, ids)
+}
+
+func xliffFileContents(set model.TranslationSet, language string, ids [][]string) string {
+	sourceLanguage := ""
+	if 0 < len(set.Languages) {
+		sourceLanguage = set.Languages[0]
+	}
+
+	ret := "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n" +
+		"<xliff version=\"1.2\" xmlns=\"urn:oasis:names:tc:xliff:document:1.2\">\n" +
+		"  <!-- Generated by Sanat -->\n"
+	ret += fmt.Sprintf("  <file original=\"translations\" datatype=\"plaintext\" source-language=\"%s\" target-language=\"%s\">\n",
+		util.XMLEscaped(sourceLanguage), util.XMLEscaped(language))
+	ret += "    <body>\n"
+	for sectionIndex, section := range set.Sections {
+		indentation := "      "
+		if 0 < len(section.Name) {
+			indentation += "  "
+		}
+		transUnits := ""
+		for translationIndex, translation := range section.Translations {
+			if 0 < len(ids[sectionIndex][translationIndex]) {
+				transUnits += transUnitString(translation, sourceLanguage, language, indentation)
+			}
+		}
+		if len(transUnits) == 0 {
+			continue
+		}
+		if 0 < len(section.Name) {
+			ret += "      <group resname=\"" + util.XMLEscaped(section.Name) + "\">\n" + transUnits + "      </group>\n"
+		} else {
+			ret += transUnits
+		}
+	}
+	ret += "    </body>\n" +
+		"  </file>\n" +
+		"</xliff>\n"
+	return ret
+}
+
+// GetXLIFFFiles returns the contents of all the files that
+// WriteXLIFFFiles writes, keyed by path relative to the output
+// directory.
+func GetXLIFFFiles(set model.TranslationSet) map[string]string {
+	ids, warnings := transUnitIDs(set)
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "WARNING: "+warning)
+	}
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[language+".xliff"] = xliffFileContents(set, language, ids)
+	}
+	return ret
+}
+
+func WriteXLIFFFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetXLIFFFiles(set))
+}
@@ -0,0 +1,79 @@
+package xliff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/xliff"
+	"hasseg.org/sanat/test"
+	"hasseg.org/sanat/util"
+)
+
+func TestXLIFFFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(position int, semanticOrderIndex int) string {
+		return xliff.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(model.DataTypeObject, -1, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, `<x id="1" equiv-text="{0}"/>`, val(0, -1), "")
+	assert.Equal(t, `<x id="3" equiv-text="{2}"/>`, val(2, -1), "")
+	assert.Equal(t, `<x id="3" equiv-text="{11}"/>`, val(2, 12), "Explicit order index overrides actual position")
+}
+
+func TestXLIFFFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	ts.AddSection("").AddTranslation("ok").AddValue("en-US", []model.Segment{model.NewTextSegment("OK")})
+	section := ts.AddSection("Main <window>")
+	greeting := section.AddTranslation("greeting")
+	greeting.Comment = "Shown at launch"
+	greeting.MaxLength = 20
+	greeting.PlatformMaxLengths = map[model.TranslationPlatform]int{model.PlatformAndroid: 12}
+	greeting.AddValue("en-US", []model.Segment{
+		model.NewTextSegment("Hello "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(" & welcome"),
+	})
+	greeting.AddValue("fi-FI", []model.Segment{
+		model.NewTextSegment("Hei "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+	})
+	section.AddTranslation("ok").AddValue("fi-FI", []model.Segment{model.NewTextSegment("Duplicate")})
+	appleOnly := ts.AddSection("Platform specific").AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en-US", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en-US")
+	ts.AddLanguage("fi-FI")
+
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <!-- Generated by Sanat -->
+  <file original="translations" datatype="plaintext" source-language="en-US" target-language="fi-FI">
+    <body>
+      <trans-unit id="ok">
+        <source>OK</source>
+      </trans-unit>
+      <group resname="Main &lt;window&gt;">
+        <trans-unit id="greeting" maxwidth="20" size-unit="char">
+          <source>Hello <x id="1" equiv-text="{0}"/> &amp; welcome</source>
+          <target>Hei <x id="1" equiv-text="{0}"/></target>
+          <note>Shown at launch</note>
+        </trans-unit>
+      </group>
+    </body>
+  </file>
+</xliff>
+`, xliff.GetXLIFFFileContents(ts, "fi-FI"), "")
+
+	files := xliff.GetXLIFFFiles(ts)
+	assert.Equal(t, 2, len(files), "")
+	_, exists := files["en-US.xliff"]
+	assert.True(t, exists, "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	for _, language := range set.Languages {
+		assert.True(t, util.XMLIsValid(xliff.GetXLIFFFileContents(set, language)), language)
+	}
+}
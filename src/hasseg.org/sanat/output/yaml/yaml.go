@@ -0,0 +1,196 @@
+package yaml
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// FormatSpecifierStringForFormatSpecifier returns the Ruby I18n
+// interpolation for the format specifier. Floats with a fixed number of
+// decimals use the formatted `%<name>.Nf` form, everything else the
+// plain `%{name}` form.
+func FormatSpecifierStringForFormatSpecifier(segment model.FormatSpecifierSegment, position int) string {
+	name := model.ArgumentName(segment.ArgumentIndex(position))
+	if segment.DataType == model.DataTypeFloat && 0 <= segment.NumberOfDecimals {
+		return "%<" + name + ">." + strconv.Itoa(segment.NumberOfDecimals) + "f"
+	}
+	return "%{" + name + "}"
+}
+
+// Values that YAML (1.1, which is what Ruby's Psych implements) would
+// read as something other than a string if left unquoted.
+var reservedScalars = map[string]bool{
+	"~": true, "null": true, "Null": true, "NULL": true,
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"true": true, "True": true, "TRUE": true,
+	"false": true, "False": true, "FALSE": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+	"<<": true, "=": true,
+}
+
+func looksLikeNumberOrDate(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if strings.HasPrefix(s, ".") {
+		s = s[1:]
+		if lower := strings.ToLower(s); lower == "inf" || lower == "nan" {
+			return true
+		}
+	}
+	return 0 < len(s) && '0' <= s[0] && s[0] <= '9'
+}
+
+func canBePlainScalar(s string) bool {
+	if len(s) == 0 || reservedScalars[s] || looksLikeNumberOrDate(s) {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@` \t", rune(s[0])) {
+		return false
+	}
+	if util.IsWhitespace(s[len(s)-1]) || strings.HasSuffix(s, ":") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, c := range s {
+		if c < 0x20 || c == 0x7f || c == 0x85 || c == 0x2028 || c == 0x2029 || c == 0xfeff {
+			return false
+		}
+	}
+	return true
+}
+
+func doubleQuoted(s string) string {
+	ret := "\""
+	for _, c := range s {
+		switch c {
+		case '"':
+			ret += "\\\""
+		case '\\':
+			ret += "\\\\"
+		case '\n':
+			ret += "\\n"
+		case '\r':
+			ret += "\\r"
+		case '\t':
+			ret += "\\t"
+		case 0x85:
+			ret += "\\N"
+		case 0x2028:
+			ret += "\\L"
+		case 0x2029:
+			ret += "\\P"
+		default:
+			if c < 0x20 || c == 0x7f || c == 0xfeff {
+				ret += fmt.Sprintf("\\u%04x", c)
+			} else {
+				ret += string(c)
+			}
+		}
+	}
+	return ret + "\""
+}
+
+// SanitizedForYAMLScalar returns the text as a YAML scalar that is read
+// back as the same string: as-is if that is unambiguous, and as a
+// double-quoted scalar otherwise.
+func SanitizedForYAMLScalar(text string) string {
+	if canBePlainScalar(text) {
+		return text
+	}
+	return doubleQuoted(text)
+}
+
+// stringFromSegments returns the Ruby I18n string for the segments.
+// I18n only interpolates (and turns `%%` into `%`) when it is given
+// arguments, so percent signs are only doubled in values that have
+// format specifiers.
+func stringFromSegments(segments []model.Segment) string {
+	hasFormatSpecifiers := false
+	for _, segment := range segments {
+		if _, isFormatSpecifier := segment.(model.FormatSpecifierSegment); isFormatSpecifier {
+			hasFormatSpecifiers = true
+			break
+		}
+	}
+
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			text := segment.(model.TextSegment).Text
+			if hasFormatSpecifiers {
+				text = strings.Replace(text, "%", "%%", -1)
+			}
+			ret += text
+		case model.FormatSpecifierSegment:
+			ret += FormatSpecifierStringForFormatSpecifier(segment.(model.FormatSpecifierSegment), position)
+			position++
+		}
+	}
+	return ret
+}
+
+func yamlFromOrderedMap(m *util.OrderedMap, indent string) string {
+	ret := ""
+	for _, key := range m.Keys {
+		ret += indent + SanitizedForYAMLScalar(key) + ":"
+		switch value := m.Values[key].(type) {
+		case *util.OrderedMap:
+			ret += "\n" + yamlFromOrderedMap(value, indent+"  ")
+		case string:
+			ret += " " + SanitizedForYAMLScalar(value) + "\n"
+		}
+	}
+	return ret
+}
+
+// GetYAMLFileContents returns a Rails (Ruby I18n) locale file for the
+// language, with the language as the root key. Dot-separated keys are
+// split into nested mappings.
+func GetYAMLFileContents(set model.TranslationSet, language string) string {
+	messages := util.NewOrderedMap()
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if !translation.IsForPlatform(model.PlatformNone) {
+				continue
+			}
+			value := translation.ValueForLanguage(language)
+			if value == nil {
+				continue
+			}
+			if err := messages.SetAtPath(strings.Split(translation.Key, "."), stringFromSegments(value.Segments)); err != nil {
+				fmt.Fprintln(os.Stderr, "WARNING: Skipping translation '"+translation.Key+"' ("+language+"):", err.Error())
+			}
+		}
+	}
+
+	root := util.NewOrderedMap()
+	root.Set(language, messages)
+	if len(messages.Keys) == 0 {
+		return SanitizedForYAMLScalar(language) + ": {}\n"
+	}
+	return yamlFromOrderedMap(root, "")
+}
+
+// GetYAMLFiles returns the contents of all the files that WriteYAMLFiles
+// writes, keyed by path relative to the output directory.
+func GetYAMLFiles(set model.TranslationSet) map[string]string {
+	ret := make(map[string]string)
+	for _, language := range set.Languages {
+		ret[language+".yml"] = GetYAMLFileContents(set, language)
+	}
+	return ret
+}
+
+func WriteYAMLFiles(set model.TranslationSet, outDirPath string) {
+	util.WriteFiles(outDirPath, GetYAMLFiles(set))
+}
@@ -0,0 +1,104 @@
+package yaml_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/yaml"
+	"hasseg.org/sanat/test"
+)
+
+func TestYAMLFormatSpecifierStringForFormatSpecifier(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType,
+		position int,
+		numDecimals int,
+		semanticOrderIndex int) string {
+		return yaml.FormatSpecifierStringForFormatSpecifier(model.NewFormatSpecifierSegment(dataType, numDecimals, semanticOrderIndex), position)
+	}
+
+	assert.Equal(t, "%{arg1}", val(model.DataTypeObject, 0, -1, -1), "")
+	assert.Equal(t, "%{arg2}", val(model.DataTypeInteger, 1, -1, -1), "")
+	assert.Equal(t, "%{arg1}", val(model.DataTypeFloat, 0, -1, -1), "")
+	assert.Equal(t, "%<arg1>.2f", val(model.DataTypeFloat, 0, 2, -1), "")
+	assert.Equal(t, "%{arg12}", val(model.DataTypeString, 2, -1, 12), "Explicit order index overrides actual position")
+}
+
+func TestTextSanitizedForYAMLScalar(t *testing.T) {
+	ass := func(expected string, input string) {
+		assert.Equal(t, expected, yaml.SanitizedForYAMLScalar(input), input)
+	}
+
+	ass(`""`, "")
+	ass("Foo bar", "Foo bar")
+	ass("It's 5 o'clock", "It's 5 o'clock")
+	ass("a:b", "a:b")
+	ass("Hi %{arg1}", "Hi %{arg1}")
+
+	// Indicators at the start
+	ass(`"*bold*"`, "*bold*")
+	ass(`"%{arg1} left"`, "%{arg1} left")
+	ass(`"- item"`, "- item")
+	ass(`"'quoted'"`, "'quoted'")
+	ass(`"@mention"`, "@mention")
+
+	// Other values that would not be read as strings
+	ass(`"yes"`, "yes")
+	ass(`"No"`, "No")
+	ass(`"off"`, "off")
+	ass(`"~"`, "~")
+	ass(`"null"`, "null")
+	ass(`"12"`, "12")
+	ass(`"-1.5"`, "-1.5")
+	ass(`".inf"`, ".inf")
+	ass(`"2001-12-14"`, "2001-12-14")
+
+	// Mapping and comment syntax
+	ass(`"Note: this"`, "Note: this")
+	ass(`"Label:"`, "Label:")
+	ass(`"C #1"`, "C #1")
+
+	// Whitespace and escapes
+	ass(`" padded "`, " padded ")
+	ass(`"line\nbreak"`, "line\nbreak")
+	ass(`say "hi" \ bye`, "say \"hi\" \\ bye")
+	ass(`"\"hi\" \\ bye"`, "\"hi\" \\ bye")
+	ass(`"\u0007"`, "\a")
+}
+
+func TestYAMLFileContents(t *testing.T) {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	section.AddTranslation("LoginView.Title").AddValue("en", []model.Segment{model.NewTextSegment("Log in")})
+	section.AddTranslation("LoginView.Greeting").AddValue("en", []model.Segment{
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1),
+		model.NewTextSegment(", you're 100% done"),
+	})
+	section.AddTranslation("LoginView.Done").AddValue("en", []model.Segment{model.NewTextSegment("100% done")})
+	section.AddTranslation("LoginView.Title.Short").AddValue("en", []model.Segment{model.NewTextSegment("Collides")})
+	section.AddTranslation("yes").AddValue("en", []model.Segment{model.NewTextSegment("Yes")})
+	appleOnly := ts.AddSection("Platform specific").AddTranslation("AppleOnly")
+	appleOnly.Platforms = []model.TranslationPlatform{model.PlatformApple}
+	appleOnly.AddValue("en", []model.Segment{model.NewTextSegment("Not written")})
+	ts.AddLanguage("en")
+	ts.AddLanguage("fi")
+
+	assert.Equal(t, `en:
+  LoginView:
+    Title: Log in
+    Greeting: "%{arg1}, you're 100%% done"
+    Done: "100% done"
+  "yes": "Yes"
+`, yaml.GetYAMLFileContents(ts, "en"), "")
+	assert.Equal(t, "fi: {}\n", yaml.GetYAMLFileContents(ts, "fi"), "")
+
+	_, exists := yaml.GetYAMLFiles(ts)["en.yml"]
+	assert.True(t, exists, "")
+}
+
+func TestComprehensiveInput(t *testing.T) {
+	set := test.GetComprehensiveTestInputTranslationSet()
+	files := yaml.GetYAMLFiles(set)
+	assert.Equal(t, len(set.Languages), len(files), "")
+}
@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"io"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/model/jsondoc"
+)
+
+// parseJSONTranslationSet reads a translation set from the format written
+// by the `json` output format. The values in it have already been
+// preprocessed, so no preprocessors are applied.
+func (p *translationParser) parseJSONTranslationSet(inputReader io.Reader) model.TranslationSet {
+	document, lineNumber, err := jsondoc.ReadDocument(inputReader)
+	if err != nil {
+		p.lineNumber = lineNumber
+		p.reportError("Invalid JSON: " + err.Error())
+		return model.NewTranslationSet()
+	}
+
+	set, err := jsondoc.TranslationSetForDocument(document)
+	if err != nil {
+		p.reportError(err.Error())
+	}
+	return set
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 
@@ -102,6 +103,8 @@ func (p *translationParser) platformsFromCommaSeparatedString(text string) []mod
 			platform = model.PlatformAndroid
 		case "windows":
 			platform = model.PlatformWindows
+		case "web":
+			platform = model.PlatformWeb
 		}
 		if platform == model.PlatformNone {
 			p.reportError("Unknown platform value: '" + s + "'")
@@ -112,6 +115,35 @@ func (p *translationParser) platformsFromCommaSeparatedString(text string) []mod
 	return ret
 }
 
+// processMaxLength sets the maximum length of the translation from a
+// `maxlength = N` or `maxlength.<platform> = N` metadata row.
+func (p *translationParser) processMaxLength(translation *model.Translation, key string, value string) {
+	maxLength, err := strconv.Atoi(value)
+	if err != nil || maxLength <= 0 {
+		p.reportError("Invalid " + key + " value: '" + value + "' — must be a positive integer")
+		return
+	}
+
+	separatorIndex := strings.Index(key, ".")
+	if separatorIndex == -1 {
+		translation.MaxLength = maxLength
+		return
+	}
+	platformName := key[separatorIndex+1:]
+	if len(strings.TrimSpace(platformName)) == 0 || strings.Contains(platformName, ",") {
+		p.reportError("Invalid metadata key '" + key + "' — expected maxlength.<platform>")
+		return
+	}
+	platforms := p.platformsFromCommaSeparatedString(platformName)
+	if len(platforms) == 0 {
+		return
+	}
+	if translation.PlatformMaxLengths == nil {
+		translation.PlatformMaxLengths = make(map[model.TranslationPlatform]int)
+	}
+	translation.PlatformMaxLengths[platforms[0]] = maxLength
+}
+
 func (p *translationParser) segmentsFromTranslationValueString(text string) []model.Segment {
 	ret := make([]model.Segment, 0)
 
@@ -218,11 +250,13 @@ func (p *translationParser) parseTranslationSet(inputReader io.Reader, preproces
 				currentTranslation.Tags = util.ComponentsFromCommaSeparatedList(value)
 			} else if lowerKey == "comment" {
 				currentTranslation.Comment = value
+			} else if lowerKey == "maxlength" || strings.HasPrefix(lowerKey, "maxlength.") {
+				p.processMaxLength(currentTranslation, key, value)
 			} else {
 				value = preprocessor.ProcessRawValue(value)
 				segments := preprocessor.ProcessValueSegments(p.segmentsFromTranslationValueString(value))
 				currentTranslation.AddValue(key, segments)
-				set.Languages[key] = true
+				set.AddLanguage(key)
 			}
 		}
 
@@ -244,13 +278,34 @@ func (p *translationParser) parseTranslationSet(inputReader io.Reader, preproces
 	return set
 }
 
+// TranslationSetFromFile parses the translation file at inputPath. Files
+// with a .json extension are read as the output of the `json` output
+// format.
 func TranslationSetFromFile(inputPath string, preprocessor preprocessing.Preprocessor, errorHandler ParserErrorHandler) (model.TranslationSet, error) {
 	f, err := os.Open(inputPath)
 	if err != nil {
 		panic(err)
 	}
+	defer f.Close()
+
+	if strings.ToLower(path.Ext(inputPath)) != ".json" {
+		return TranslationSetFromReader(f, preprocessor, errorHandler)
+	}
+
+	parser := translationParser{errorHandler: errorHandler}
+	ret := parser.parseJSONTranslationSet(f)
+	if parser.numErrors == 0 {
+		return ret, nil
+	} else {
+		return ret, errors.New("Errors while parsing")
+	}
+}
+
+// TranslationSetFromReader parses translation file syntax (not JSON)
+// read from inputReader, e.g. the unsaved contents of an editor buffer.
+func TranslationSetFromReader(inputReader io.Reader, preprocessor preprocessing.Preprocessor, errorHandler ParserErrorHandler) (model.TranslationSet, error) {
 	parser := translationParser{errorHandler: errorHandler}
-	ret := parser.parseTranslationSet(f, preprocessor)
+	ret := parser.parseTranslationSet(inputReader, preprocessor)
 	if parser.numErrors == 0 {
 		return ret, nil
 	} else {
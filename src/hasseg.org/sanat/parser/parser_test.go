@@ -170,6 +170,31 @@ func TestParserErrorReporting(t *testing.T) {
     en =
     fi =`) // Empty values are okay (not a parser error, anyway)
 
+	assertNoError(`
+  Title
+    maxlength = 20
+    maxlength.android = 12
+    MaxLength.Web = 30
+    en = Hello world`)
+
+	assertError(`
+  Title
+    maxlength = twenty
+    en = Hello world`,
+		3, "Invalid maxlength value")
+
+	assertError(`
+  Title
+    en = Hello world
+    maxlength.ios = 10`,
+		4, "Unknown platform value")
+
+	assertError(`
+  Title
+    en = Hello world
+    maxlength.android,apple = 10`,
+		4, "expected maxlength.<platform>")
+
 	assertError(`
   Title
     platforms = xx
@@ -198,3 +223,36 @@ Title
     fi = Moro maailma`,
 		2, "Unknown un-indented line")
 }
+
+func TestTranslationSetFromReader(t *testing.T) {
+	set, err := TranslationSetFromReader(strings.NewReader(`
+  Title
+    en = Hello {s}
+    fi = Moro`), preprocessing.NewNoOpPreprocessor(), nil)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []string{"en", "fi"}, set.Languages, "")
+	assert.Equal(t, "Title", set.Sections[0].Translations[0].Key, "")
+
+	errorLineNumbers := make([]int, 0)
+	_, err = TranslationSetFromReader(strings.NewReader("\nTitle\n"), preprocessing.NewNoOpPreprocessor(), func(lineNumber int, message string) {
+		errorLineNumbers = append(errorLineNumbers, lineNumber)
+	})
+	assert.NotNil(t, err, "")
+	assert.Equal(t, []int{2}, errorLineNumbers, "")
+}
+
+func TestMaxLengthMetadata(t *testing.T) {
+	set, err := TranslationSetFromReader(strings.NewReader(`
+  Title
+    maxlength = 20
+    maxlength.android = 12
+    MaxLength.Web = 30
+    en = Hello world
+  Other
+    en = Foo`), preprocessing.NewNoOpPreprocessor(), nil)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 20, set.Sections[0].Translations[0].MaxLength, "")
+	assert.Equal(t, map[model.TranslationPlatform]int{model.PlatformAndroid: 12, model.PlatformWeb: 30}, set.Sections[0].Translations[0].PlatformMaxLengths, "")
+	assert.Equal(t, []string{"en"}, set.Languages, "maxlength is not a language")
+	assert.Equal(t, 0, set.Sections[0].Translations[1].MaxLength, "")
+}
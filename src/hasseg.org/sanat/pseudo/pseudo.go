@@ -0,0 +1,160 @@
+// Package pseudo synthesizes pseudo-localized languages from the source
+// language of a translation set, for finding truncation, hard-coded
+// strings and other localization bugs without real translations.
+package pseudo
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// The conventional language codes for the accented and the
+// right-to-left pseudo-locales
+const (
+	DefaultLanguage    = "en-XA"
+	DefaultRTLLanguage = "ar-XB"
+)
+
+var accentedLetters = map[rune]rune{
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// AccentedText replaces the ASCII letters in text with accented
+// look-alikes (leaving markup untouched.)
+func AccentedText(text string) string {
+	return util.MapNonMarkupParts(text, func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if accented, ok := accentedLetters[r]; ok {
+				return accented
+			}
+			return r
+		}, s)
+	})
+}
+
+// visibleLength returns the number of characters in the text segments,
+// not counting markup.
+func visibleLength(segments []model.Segment) int {
+	ret := 0
+	for _, segment := range segments {
+		if textSegment, ok := segment.(model.TextSegment); ok {
+			util.MapNonMarkupParts(textSegment.Text, func(s string) string {
+				ret += utf8.RuneCountInString(s)
+				return s
+			})
+		}
+	}
+	return ret
+}
+
+// PseudoLocalizedSegments returns the segments with the letters in the
+// text accented, padding (of expansionPercent of the length of the
+// text) added to the end, and the whole thing wrapped in brackets. The
+// format specifiers and markup are left as they are. Empty values stay
+// empty.
+func PseudoLocalizedSegments(segments []model.Segment, expansionPercent int) []model.Segment {
+	if len(segments) == 0 {
+		return segments
+	}
+	paddingLength := 0
+	if 0 < expansionPercent {
+		paddingLength = (visibleLength(segments)*expansionPercent + 99) / 100
+	}
+
+	ret := []model.Segment{model.NewTextSegment("[")}
+	for _, segment := range segments {
+		if textSegment, ok := segment.(model.TextSegment); ok {
+			ret = append(ret, model.NewTextSegment(AccentedText(textSegment.Text)))
+		} else {
+			ret = append(ret, segment)
+		}
+	}
+	ret = append(ret, model.NewTextSegment(strings.Repeat("~", paddingLength)+"]"))
+	return mergedTextSegments(ret)
+}
+
+// mergedTextSegments joins adjacent text segments into one.
+func mergedTextSegments(segments []model.Segment) []model.Segment {
+	ret := make([]model.Segment, 0)
+	for _, segment := range segments {
+		textSegment, isText := segment.(model.TextSegment)
+		if isText && 0 < len(ret) {
+			if previous, previousIsText := ret[len(ret)-1].(model.TextSegment); previousIsText {
+				ret[len(ret)-1] = model.NewTextSegment(previous.Text + textSegment.Text)
+				continue
+			}
+		}
+		ret = append(ret, segment)
+	}
+	return ret
+}
+
+// Bidi control characters
+const (
+	rightToLeftMark          = "\u200F"
+	rightToLeftOverride      = "\u202E"
+	popDirectionalFormatting = "\u202C"
+)
+
+// BidiOverriddenText wraps each word in text in RLO…PDF (right-to-left
+// override) marks so that it is displayed mirrored, and surrounds them
+// with RLM (right-to-left mark) characters so that the words are laid
+// out right-to-left (leaving markup untouched.)
+func BidiOverriddenText(text string) string {
+	return util.MapNonMarkupParts(text, func(s string) string {
+		return wordRegexp.ReplaceAllString(s, rightToLeftMark+rightToLeftOverride+"$0"+popDirectionalFormatting+rightToLeftMark)
+	})
+}
+
+var wordRegexp = regexp.MustCompile(`[^\s]+`)
+
+// RTLPseudoLocalizedSegments returns the segments with the words in the
+// text bidi-overridden with BidiOverriddenText, so that a left-to-right
+// source language can be used for finding layout bugs that only occur
+// with right-to-left languages. The format specifiers and markup are
+// left as they are.
+func RTLPseudoLocalizedSegments(segments []model.Segment) []model.Segment {
+	ret := make([]model.Segment, 0)
+	for _, segment := range segments {
+		if textSegment, ok := segment.(model.TextSegment); ok {
+			ret = append(ret, model.NewTextSegment(BidiOverriddenText(textSegment.Text)))
+		} else {
+			ret = append(ret, segment)
+		}
+	}
+	return ret
+}
+
+// AddPseudoLanguage adds a value for language to every translation that
+// has a value for the source language (the first one in the set),
+// pseudo-localized from it with transform (e.g. RTLPseudoLocalizedSegments.)
+func AddPseudoLanguage(set *model.TranslationSet, language string, transform func([]model.Segment) []model.Segment) error {
+	if len(set.Languages) == 0 {
+		return errors.New("Cannot add the pseudo-language '" + language + "': there is no source language")
+	}
+	if set.HasLanguage(language) {
+		return errors.New("Cannot add the pseudo-language '" + language + "': the language already exists")
+	}
+
+	sourceLanguage := set.Languages[0]
+	for i := range set.Sections {
+		for j := range set.Sections[i].Translations {
+			translation := &set.Sections[i].Translations[j]
+			if value := translation.ValueForLanguage(sourceLanguage); value != nil {
+				translation.AddValue(language, transform(value.Segments))
+			}
+		}
+	}
+	set.AddLanguage(language)
+	return nil
+}
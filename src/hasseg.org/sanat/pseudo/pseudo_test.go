@@ -0,0 +1,74 @@
+package pseudo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/android"
+	"hasseg.org/sanat/parser"
+	"hasseg.org/sanat/preprocessing"
+	"hasseg.org/sanat/pseudo"
+)
+
+func TestAccentedText(t *testing.T) {
+	assert.Equal(t, "Ĥéļļö, Ŵöŕļð! 123", pseudo.AccentedText("Hello, World! 123"), "")
+	assert.Equal(t, "<a href=\"x\">Ļîñķ</a> &amp; &#39;ţéẋţ&#39;", pseudo.AccentedText("<a href=\"x\">Link</a> &amp; &#39;text&#39;"), "Markup is not accented")
+	assert.Equal(t, "Ţöţáļ: äö", pseudo.AccentedText("Total: äö"), "")
+}
+
+func TestPseudoLocalizedSegments(t *testing.T) {
+	specifier := model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1)
+
+	assert.Equal(t, []model.Segment{model.NewTextSegment("[Ĥéļļö~~]")},
+		pseudo.PseudoLocalizedSegments([]model.Segment{model.NewTextSegment("Hello")}, 30), "Padding is rounded up")
+	assert.Equal(t, []model.Segment{model.NewTextSegment("[Ĥéļļö]")},
+		pseudo.PseudoLocalizedSegments([]model.Segment{model.NewTextSegment("Hello")}, 0), "")
+	assert.Equal(t,
+		[]model.Segment{model.NewTextSegment("["), specifier, model.NewTextSegment(" <b>ţöţáļ</b>~~~~~~]")},
+		pseudo.PseudoLocalizedSegments([]model.Segment{specifier, model.NewTextSegment(" <b>total</b>")}, 100),
+		"Format specifiers and markup are not counted in the length")
+	assert.Equal(t, []model.Segment{}, pseudo.PseudoLocalizedSegments([]model.Segment{}, 30), "Empty values stay empty")
+}
+
+func TestRTLPseudoLocalizedSegments(t *testing.T) {
+	specifier := model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1)
+
+	assert.Equal(t, "\u200F\u202EHello,\u202C\u200F  <b>\u200F\u202Eworld\u202C\u200F</b>", pseudo.BidiOverriddenText("Hello,  <b>world</b>"), "")
+	assert.Equal(t,
+		[]model.Segment{model.NewTextSegment("\u200F\u202EHi\u202C\u200F "), specifier, model.NewTextSegment("\u200F\u202E!\u202C\u200F")},
+		pseudo.RTLPseudoLocalizedSegments([]model.Segment{model.NewTextSegment("Hi "), specifier, model.NewTextSegment("!")}),
+		"Format specifiers are not wrapped")
+}
+
+func TestAddPseudoLanguage(t *testing.T) {
+	set, _ := parser.TranslationSetFromFile("../output/testdata/comprehensive.sanat", preprocessing.NewNoOpPreprocessor(), nil)
+	numLanguages := len(set.Languages)
+	sourceLanguage := set.Languages[0]
+
+	transform := func(segments []model.Segment) []model.Segment {
+		return pseudo.PseudoLocalizedSegments(segments, 30)
+	}
+	assert.Nil(t, pseudo.AddPseudoLanguage(&set, pseudo.DefaultLanguage, transform), "")
+	assert.Equal(t, numLanguages+1, len(set.Languages), "")
+	assert.Equal(t, pseudo.DefaultLanguage, set.Languages[numLanguages], "")
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			sourceValue := translation.ValueForLanguage(sourceLanguage)
+			pseudoValue := translation.ValueForLanguage(pseudo.DefaultLanguage)
+			if sourceValue == nil {
+				assert.Nil(t, pseudoValue, translation.Key)
+				continue
+			}
+			assert.Equal(t, pseudo.PseudoLocalizedSegments(sourceValue.Segments, 30), pseudoValue.Segments, translation.Key)
+			assert.Equal(t, sourceValue.Arguments(), pseudoValue.Arguments(), translation.Key)
+		}
+	}
+	_, exists := android.GetStringsFiles(set)["values-"+pseudo.DefaultLanguage+"/strings.xml"]
+	assert.True(t, exists, "Writers output the pseudo-language like any other language")
+
+	assert.NotNil(t, pseudo.AddPseudoLanguage(&set, pseudo.DefaultLanguage, transform), "The language already exists")
+	empty := model.NewTranslationSet()
+	assert.NotNil(t, pseudo.AddPseudoLanguage(&empty, pseudo.DefaultLanguage, transform), "No source language")
+}
@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"hasseg.org/sanat/model"
+)
+
+// FormatArgument returns the argument formatted as specified by the
+// format specifier. Strings must be given as strings (or fmt.Stringers),
+// integers as any integer type and floats as any integer or float type.
+// Objects can be anything; they are formatted with fmt.Sprint().
+func FormatArgument(segment model.FormatSpecifierSegment, argument interface{}) (string, error) {
+	switch segment.DataType {
+	case model.DataTypeString:
+		switch argument.(type) {
+		case string:
+			return argument.(string), nil
+		case fmt.Stringer:
+			return argument.(fmt.Stringer).String(), nil
+		}
+	case model.DataTypeInteger:
+		value := reflect.ValueOf(argument)
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(value.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return strconv.FormatUint(value.Uint(), 10), nil
+		}
+	case model.DataTypeFloat:
+		var f float64
+		value := reflect.ValueOf(argument)
+		switch value.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = value.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			f = float64(value.Uint())
+		default:
+			return "", fmt.Errorf("expected a number, got %T", argument)
+		}
+		return strconv.FormatFloat(f, 'f', segment.NumberOfDecimals, 64), nil
+	default:
+		return fmt.Sprint(argument), nil
+	}
+	return "", fmt.Errorf("expected %s, got %T", dataTypeDescription(segment.DataType), argument)
+}
+
+func dataTypeDescription(dataType model.TranslationFormatDataType) string {
+	switch dataType {
+	case model.DataTypeString:
+		return "a string"
+	case model.DataTypeInteger:
+		return "an integer"
+	case model.DataTypeFloat:
+		return "a number"
+	}
+	return "an object"
+}
+
+// FormatSegments formats the segments of a translation value with the
+// given arguments. Each format specifier is replaced by the argument it
+// refers to (see model.FormatSpecifierSegment.ArgumentIndex), formatted
+// with FormatArgument.
+func FormatSegments(segments []model.Segment, arguments ...interface{}) (string, error) {
+	ret := ""
+	position := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			ret += segment.(model.TextSegment).Text
+		case model.FormatSpecifierSegment:
+			specifier := segment.(model.FormatSpecifierSegment)
+			index := specifier.ArgumentIndex(position)
+			position++
+			if len(arguments) <= index {
+				return "", fmt.Errorf("missing argument %d (got %d)", index+1, len(arguments))
+			}
+			formatted, err := FormatArgument(specifier, arguments[index])
+			if err != nil {
+				return "", fmt.Errorf("argument %d: %s", index+1, err.Error())
+			}
+			ret += formatted
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,118 @@
+package runtime
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language identifiers are matched as described in BCP 47 (RFC 4647):
+// case-insensitively, and by removing subtags from the end of the
+// requested identifier until one matches. (Single-character subtags,
+// which start extensions, are removed together with the subtag that
+// follows them.)
+
+func normalizedLanguage(language string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(language), "_", "-", -1))
+}
+
+// TruncatedLanguages returns the language followed by the less specific
+// identifiers that it falls back to, e.g. "zh-Hant-TW" → "zh-Hant-TW",
+// "zh-Hant", "zh".
+func TruncatedLanguages(language string) []string {
+	ret := make([]string, 0)
+	subtags := strings.Split(strings.Replace(strings.TrimSpace(language), "_", "-", -1), "-")
+	for len(subtags) != 0 && len(subtags[0]) != 0 {
+		ret = append(ret, strings.Join(subtags, "-"))
+		subtags = subtags[:len(subtags)-1]
+		if 1 < len(subtags) && len(subtags[len(subtags)-1]) == 1 {
+			subtags = subtags[:len(subtags)-1]
+		}
+	}
+	return ret
+}
+
+func primaryLanguage(language string) string {
+	return strings.SplitN(normalizedLanguage(language), "-", 2)[0]
+}
+
+// exactLanguage returns the one of the available languages that is the
+// same as the given one (ignoring case and separators), or false if
+// there is none.
+func exactLanguage(available []string, language string) (string, bool) {
+	for _, availableLanguage := range available {
+		if normalizedLanguage(availableLanguage) == normalizedLanguage(language) {
+			return availableLanguage, true
+		}
+	}
+	return "", false
+}
+
+// samePrimaryLanguage returns the first one of the available languages
+// with the same primary language subtag as the given one, or false if
+// there is none.
+func samePrimaryLanguage(available []string, language string) (string, bool) {
+	for _, availableLanguage := range available {
+		if primaryLanguage(availableLanguage) == primaryLanguage(language) {
+			return availableLanguage, true
+		}
+	}
+	return "", false
+}
+
+// MatchLanguage returns the one of the available languages that best
+// matches the requested languages (in order of preference), or false if
+// none of them match. A requested language matches an available one if
+// it, or one of its truncations (see TruncatedLanguages), is the same
+// as the available one. Failing that, the first available language with
+// the same primary language subtag (e.g. "en-US" for "en-GB") is used.
+func MatchLanguage(available []string, requested []string) (string, bool) {
+	for _, requestedLanguage := range requested {
+		for _, candidate := range TruncatedLanguages(requestedLanguage) {
+			if matched, ok := exactLanguage(available, candidate); ok {
+				return matched, true
+			}
+		}
+	}
+	for _, requestedLanguage := range requested {
+		if matched, ok := samePrimaryLanguage(available, requestedLanguage); ok {
+			return matched, true
+		}
+	}
+	return "", false
+}
+
+// ParseAcceptLanguage returns the languages in an HTTP Accept-Language
+// header value, ordered by their quality values. The "*" wildcard and
+// languages with a quality of 0 are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weightedLanguage struct {
+		language string
+		quality  float64
+	}
+	weighted := make([]weightedLanguage, 0)
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(item, ";")
+		language := strings.TrimSpace(parts[0])
+		quality := 1.0
+		for _, parameter := range parts[1:] {
+			parameter = strings.TrimSpace(parameter)
+			if strings.HasPrefix(parameter, "q=") {
+				if q, err := strconv.ParseFloat(parameter[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if len(language) == 0 || language == "*" || quality <= 0 {
+			continue
+		}
+		weighted = append(weighted, weightedLanguage{language, quality})
+	}
+	sort.SliceStable(weighted, func(i, j int) bool { return weighted[i].quality > weighted[j].quality })
+
+	ret := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		ret = append(ret, w.language)
+	}
+	return ret
+}
@@ -0,0 +1,155 @@
+// Package runtime loads Sanat translation files (or the output of the
+// json format) and formats the translations at runtime, without going
+// through any platform-specific resource format.
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/parser"
+	"hasseg.org/sanat/preprocessing"
+)
+
+// ErrNotFound is returned (wrapped) by Format when there is no value for
+// the key in any of the languages that the requested language falls back
+// to.
+var ErrNotFound = errors.New("translation not found")
+
+// Translations provides access to the values of a translation set.
+// Lookups are safe for concurrent use once the fallbacks have been set
+// up.
+type Translations struct {
+	Set model.TranslationSet
+
+	// DefaultLanguage is the language that all languages finally fall
+	// back to. It is the first language of the set by default.
+	DefaultLanguage string
+
+	translationsByKey map[string]model.Translation
+	fallbacks         map[string][]string
+}
+
+// New returns Translations for the given set.
+func New(set model.TranslationSet) *Translations {
+	ret := &Translations{
+		Set:               set,
+		translationsByKey: make(map[string]model.Translation),
+		fallbacks:         make(map[string][]string),
+	}
+	if 0 < len(set.Languages) {
+		ret.DefaultLanguage = set.Languages[0]
+	}
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if _, exists := ret.translationsByKey[translation.Key]; !exists {
+				ret.translationsByKey[translation.Key] = translation
+			}
+		}
+	}
+	return ret
+}
+
+// Load parses the translation file at filePath (a .sanat file, or a
+// .json file written by the json output format) with the given
+// preprocessor (or none, if nil.) Parser errors are returned as a single
+// error, one per line.
+func Load(filePath string, preprocessor preprocessing.Preprocessor) (*Translations, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, err
+	}
+	if preprocessor == nil {
+		preprocessor = preprocessing.NewNoOpPreprocessor()
+	}
+	parserErrors := make([]string, 0)
+	set, err := parser.TranslationSetFromFile(filePath, preprocessor, func(lineNumber int, message string) {
+		parserErrors = append(parserErrors, fmt.Sprintf("%s:%d: %s", filePath, lineNumber, message))
+	})
+	if err != nil {
+		return nil, errors.New(strings.Join(parserErrors, "\n"))
+	}
+	return New(set), nil
+}
+
+// SetFallbacks sets the languages that language falls back to (after
+// its truncations, e.g. "nb-NO" → "nb"), before the default language.
+func (t *Translations) SetFallbacks(language string, fallbacks ...string) {
+	t.fallbacks[normalizedLanguage(language)] = fallbacks
+}
+
+// FallbackChain returns the languages of the set in which values for the
+// given language are looked up, in order: the language itself and its
+// truncations, their fallbacks (see SetFallbacks), the first language
+// of the set with the same primary language subtag (e.g. "en-US" for
+// "en-GB") and finally the default language.
+func (t *Translations) FallbackChain(language string) []string {
+	ret := make([]string, 0)
+	seen := make(map[string]bool)
+	addMatched := func(matched string, ok bool) {
+		if ok && !seen[matched] {
+			ret = append(ret, matched)
+			seen[matched] = true
+		}
+	}
+	add := func(candidate string) {
+		addMatched(exactLanguage(t.Set.Languages, candidate))
+	}
+
+	visitedFallbacks := make(map[string]bool)
+	var addWithFallbacks func(language string)
+	addWithFallbacks = func(language string) {
+		truncated := TruncatedLanguages(language)
+		for _, candidate := range truncated {
+			add(candidate)
+		}
+		for _, candidate := range truncated {
+			if visitedFallbacks[normalizedLanguage(candidate)] {
+				continue
+			}
+			visitedFallbacks[normalizedLanguage(candidate)] = true
+			for _, fallback := range t.fallbacks[normalizedLanguage(candidate)] {
+				addWithFallbacks(fallback)
+			}
+		}
+	}
+	addWithFallbacks(language)
+	addMatched(samePrimaryLanguage(t.Set.Languages, language))
+	if 0 < len(t.DefaultLanguage) {
+		add(t.DefaultLanguage)
+	}
+	return ret
+}
+
+// Value returns the value of the translation with the given key for the
+// first language in the fallback chain of the given language that has
+// one.
+func (t *Translations) Value(language string, key string) (*model.TranslationValue, bool) {
+	translation, exists := t.translationsByKey[key]
+	if !exists {
+		return nil, false
+	}
+	for _, candidate := range t.FallbackChain(language) {
+		if value := translation.ValueForLanguage(candidate); value != nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// Format returns the value of the translation with the given key for the
+// given language (see Value), formatted with the given arguments (see
+// FormatSegments.)
+func (t *Translations) Format(language string, key string, arguments ...interface{}) (string, error) {
+	value, found := t.Value(language, key)
+	if !found {
+		return "", fmt.Errorf("%q (%s): %w", key, language, ErrNotFound)
+	}
+	ret, err := FormatSegments(value.Segments, arguments...)
+	if err != nil {
+		return "", fmt.Errorf("%q (%s): %s", key, value.Language, err.Error())
+	}
+	return ret, nil
+}
@@ -0,0 +1,200 @@
+package runtime_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/json"
+	"hasseg.org/sanat/runtime"
+)
+
+const comprehensiveInputPath = "../output/testdata/comprehensive.sanat"
+
+type stringer struct{}
+
+func (s stringer) String() string { return "stringer" }
+
+func TestFormatArgument(t *testing.T) {
+	val := func(dataType model.TranslationFormatDataType, numDecimals int, argument interface{}) string {
+		ret, err := runtime.FormatArgument(model.NewFormatSpecifierSegment(dataType, numDecimals, -1), argument)
+		if err != nil {
+			return "ERROR"
+		}
+		return ret
+	}
+
+	assert.Equal(t, "foo", val(model.DataTypeString, -1, "foo"), "")
+	assert.Equal(t, "stringer", val(model.DataTypeString, -1, stringer{}), "")
+	assert.Equal(t, "ERROR", val(model.DataTypeString, -1, 42), "")
+
+	assert.Equal(t, "42", val(model.DataTypeInteger, -1, 42), "")
+	assert.Equal(t, "-7", val(model.DataTypeInteger, -1, int8(-7)), "")
+	assert.Equal(t, "7", val(model.DataTypeInteger, -1, uint64(7)), "")
+	assert.Equal(t, "ERROR", val(model.DataTypeInteger, -1, 4.2), "")
+
+	assert.Equal(t, "3.14159", val(model.DataTypeFloat, -1, 3.14159), "")
+	assert.Equal(t, "3.14", val(model.DataTypeFloat, 2, 3.14159), "")
+	assert.Equal(t, "3", val(model.DataTypeFloat, 0, float32(3.14159)), "")
+	assert.Equal(t, "2.0", val(model.DataTypeFloat, 1, 2), "Integers are accepted as floats")
+	assert.Equal(t, "ERROR", val(model.DataTypeFloat, 1, "2"), "")
+
+	assert.Equal(t, "[1 2]", val(model.DataTypeObject, -1, []int{1, 2}), "")
+}
+
+func TestFormatSegments(t *testing.T) {
+	segments := []model.Segment{
+		model.NewTextSegment("Hi "),
+		model.NewFormatSpecifierSegment(model.DataTypeString, -1, 2),
+		model.NewTextSegment(", you owe "),
+		model.NewFormatSpecifierSegment(model.DataTypeFloat, 2, 1),
+	}
+	ret, err := runtime.FormatSegments(segments, 12.5, "Ali")
+	assert.Nil(t, err, "")
+	assert.Equal(t, "Hi Ali, you owe 12.50", ret, "Explicit order indexes are honored")
+
+	_, err = runtime.FormatSegments(segments, 12.5)
+	assert.EqualError(t, err, "missing argument 2 (got 1)", "")
+
+	_, err = runtime.FormatSegments(segments, "12.5", "Ali")
+	assert.EqualError(t, err, "argument 1: expected a number, got string", "")
+}
+
+func TestTruncatedLanguages(t *testing.T) {
+	assert.Equal(t, []string{"zh-Hant-TW", "zh-Hant", "zh"}, runtime.TruncatedLanguages("zh-Hant-TW"), "")
+	assert.Equal(t, []string{"de-CH-x-phonebk", "de-CH", "de"}, runtime.TruncatedLanguages("de-CH-x-phonebk"), "Singletons are removed with the following subtag")
+	assert.Equal(t, []string{"fi-FI", "fi"}, runtime.TruncatedLanguages("fi_FI"), "Underscores are accepted as separators")
+	assert.Equal(t, []string{}, runtime.TruncatedLanguages(""), "")
+}
+
+func TestMatchLanguage(t *testing.T) {
+	available := []string{"en-US", "fi", "zh-Hant"}
+	match := func(requested ...string) string {
+		ret, _ := runtime.MatchLanguage(available, requested)
+		return ret
+	}
+
+	assert.Equal(t, "fi", match("fi"), "")
+	assert.Equal(t, "fi", match("FI-fi"), "Case-insensitive")
+	assert.Equal(t, "fi", match("fi-FI"), "Truncation")
+	assert.Equal(t, "zh-Hant", match("zh-Hant-TW"), "")
+	assert.Equal(t, "fi", match("sv", "fi-FI", "en-US"), "Order of preference")
+	assert.Equal(t, "en-US", match("en-GB"), "Same primary language")
+	assert.Equal(t, "fi", match("en-GB", "fi"), "Truncation matches are preferred")
+	assert.Equal(t, "", match("sv"), "")
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fi-FI", "fi", "en"}, runtime.ParseAcceptLanguage("en;q=0.5, fi-FI, fi;q=0.8, *;q=0.1, sv;q=0"), "")
+	assert.Equal(t, []string{}, runtime.ParseAcceptLanguage(""), "")
+}
+
+func makeTranslations() *runtime.Translations {
+	ts := model.NewTranslationSet()
+	section := ts.AddSection("")
+	greeting := section.AddTranslation("Greeting")
+	greeting.AddValue("en", []model.Segment{model.NewTextSegment("Hello "), model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1)})
+	greeting.AddValue("nb", []model.Segment{model.NewTextSegment("Hei "), model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1)})
+	section.AddTranslation("Bye").AddValue("en", []model.Segment{model.NewTextSegment("Bye")})
+	danish := section.AddTranslation("Danish")
+	danish.AddValue("en", []model.Segment{model.NewTextSegment("Danish")})
+	danish.AddValue("da", []model.Segment{model.NewTextSegment("Dansk")})
+	ts.AddLanguage("en")
+	ts.AddLanguage("nb")
+	ts.AddLanguage("da")
+	return runtime.New(ts)
+}
+
+func TestFallbackChain(t *testing.T) {
+	translations := makeTranslations()
+	assert.Equal(t, []string{"nb", "en"}, translations.FallbackChain("nb-NO"), "")
+	assert.Equal(t, []string{"en"}, translations.FallbackChain("no"), "")
+
+	translations.SetFallbacks("no", "nb", "da")
+	translations.SetFallbacks("nb", "da", "no")
+	assert.Equal(t, []string{"nb", "da", "en"}, translations.FallbackChain("no-NO"), "")
+	assert.Equal(t, []string{"nb", "da", "en"}, translations.FallbackChain("nb"), "Cycles are ignored")
+
+	translations.DefaultLanguage = "da"
+	assert.Equal(t, []string{"da"}, translations.FallbackChain("sv"), "")
+}
+
+func TestFallbackChainPrefersExplicitFallbacks(t *testing.T) {
+	ts := model.NewTranslationSet()
+	ts.AddLanguage("en")
+	ts.AddLanguage("zh-Hans")
+	ts.AddLanguage("zh-Hant")
+	translations := runtime.New(ts)
+	assert.Equal(t, []string{"zh-Hans", "en"}, translations.FallbackChain("zh-TW"), "Same primary language")
+
+	translations.SetFallbacks("zh-TW", "zh-Hant")
+	assert.Equal(t, []string{"zh-Hant", "zh-Hans", "en"}, translations.FallbackChain("zh-TW"), "Fallbacks before the same primary language")
+	assert.Equal(t, []string{"zh-Hans", "en"}, translations.FallbackChain("zh_hans_CN"), "")
+}
+
+func TestFormat(t *testing.T) {
+	translations := makeTranslations()
+	translations.SetFallbacks("nb", "da")
+
+	val := func(language string, key string, arguments ...interface{}) string {
+		ret, err := translations.Format(language, key, arguments...)
+		if err != nil {
+			return "ERROR: " + err.Error()
+		}
+		return ret
+	}
+
+	assert.Equal(t, "Hei Ali", val("nb-NO", "Greeting", "Ali"), "")
+	assert.Equal(t, "Hello Ali", val("sv", "Greeting", "Ali"), "")
+	assert.Equal(t, "Bye", val("nb", "Bye"), "")
+	assert.Equal(t, "Dansk", val("nb", "Danish"), "")
+	assert.Equal(t, `ERROR: "Greeting" (nb): argument 1: expected a string, got int`, val("nb", "Greeting", 1), "")
+
+	_, err := translations.Format("en", "Nope")
+	assert.True(t, errors.Is(err, runtime.ErrNotFound), "")
+}
+
+func TestLoad(t *testing.T) {
+	translations, err := runtime.Load(comprehensiveInputPath, nil)
+	assert.Nil(t, err, "")
+	ret, err := translations.Format("sv", "Vapaa")
+	assert.Nil(t, err, "")
+	assert.Equal(t, "Hej \"då\" foo", ret, "")
+
+	_, err = runtime.Load("nonexistent.sanat", nil)
+	assert.NotNil(t, err, "")
+}
+
+func TestLoadJSON(t *testing.T) {
+	translations, err := runtime.Load(comprehensiveInputPath, nil)
+	assert.Nil(t, err, "")
+
+	dir, err := ioutil.TempDir("", "sanat-runtime-test")
+	assert.Nil(t, err, "")
+	defer os.RemoveAll(dir)
+	json.WriteJSONFile(translations.Set, dir)
+
+	fromJSON, err := runtime.Load(filepath.Join(dir, "translations.json"), nil)
+	assert.Nil(t, err, "")
+	for _, language := range translations.Set.Languages {
+		expected, _ := translations.Format(language, "Eka", "a", 1.5, 2.25)
+		actual, _ := fromJSON.Format(language, "Eka", "a", 1.5, 2.25)
+		assert.Equal(t, expected, actual, language)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sanat-runtime-test")
+	assert.Nil(t, err, "")
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "invalid.json")
+	assert.Nil(t, ioutil.WriteFile(filePath, []byte("{"), 0644), "")
+
+	_, err = runtime.Load(filePath, nil)
+	assert.NotNil(t, err, "")
+}
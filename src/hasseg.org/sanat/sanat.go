@@ -2,31 +2,147 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/docopt/docopt-go"
 
+	"hasseg.org/sanat/lsp"
+	"hasseg.org/sanat/model"
 	"hasseg.org/sanat/output"
+	"hasseg.org/sanat/output/android"
+	"hasseg.org/sanat/output/golang"
+	"hasseg.org/sanat/output/java"
+	"hasseg.org/sanat/output/windows"
 	"hasseg.org/sanat/parser"
 	"hasseg.org/sanat/preprocessing"
+	"hasseg.org/sanat/pseudo"
+	"hasseg.org/sanat/server"
 	"hasseg.org/sanat/util"
+	"hasseg.org/sanat/validation"
+)
+
+// How often the input file is checked for changes with --watch, and
+// how long it has to stay unchanged before output is regenerated
+const (
+	watchInterval    = 200 * time.Millisecond
+	watchQuietPeriod = 300 * time.Millisecond
 )
 
 func parserErrorHandler(lineNumber int, message string) {
 	fmt.Fprintln(os.Stderr, "ERROR on line", lineNumber, message)
 }
 
+// setOutputOptions sets the options of the output formats from args.
+func setOutputOptions(args map[string]interface{}) {
+	if bundleNameArg := args["--bundle-name"]; bundleNameArg != nil {
+		java.BundleBaseName = bundleNameArg.(string)
+	}
+	if packageArg := args["--package"]; packageArg != nil {
+		android.KotlinPackageName = packageArg.(string)
+		windows.CSharpNamespace = packageArg.(string)
+		golang.PackageName = packageArg.(string)
+	}
+}
+
+// generate writes (or with --check, checks) the files of the output
+// format given in args.
+func generate(translationSet model.TranslationSet, args map[string]interface{}) error {
+	if languagesArg := args["--languages"]; languagesArg != nil {
+		translationSet.OrderLanguages(util.ComponentsFromCommaSeparatedList(languagesArg.(string)))
+	}
+
+	if pseudoArg := args["--pseudo"]; pseudoArg != nil {
+		expansionPercent, err := strconv.Atoi(args["--pseudo-expansion"].(string))
+		if err != nil || expansionPercent < 0 {
+			return fmt.Errorf("Invalid --pseudo-expansion value: %v", args["--pseudo-expansion"])
+		}
+		transform := func(segments []model.Segment) []model.Segment {
+			return pseudo.PseudoLocalizedSegments(segments, expansionPercent)
+		}
+		if err := pseudo.AddPseudoLanguage(&translationSet, pseudoArg.(string), transform); err != nil {
+			return err
+		}
+	}
+	if pseudoRTLArg := args["--pseudo-rtl"]; pseudoRTLArg != nil {
+		if err := pseudo.AddPseudoLanguage(&translationSet, pseudoRTLArg.(string), pseudo.RTLPseudoLocalizedSegments); err != nil {
+			return err
+		}
+	}
+
+	outputDirPath := args["<output_dir>"].(string)
+	outputFormat := args["<output_format>"].(string)
+
+	// Check output
+	//
+	if args["--check"].(bool) {
+		filesFunction, err := output.FilesFunctionForName(outputFormat)
+		if err != nil {
+			return err
+		}
+		diffs := output.DiffsForOutdatedFiles(filesFunction(translationSet), outputDirPath)
+		for _, diff := range diffs {
+			fmt.Print(diff)
+		}
+		if 0 < len(diffs) {
+			return fmt.Errorf("%d generated file(s) out of date in %s", len(diffs), outputDirPath)
+		}
+		return nil
+	}
+
+	// Write output
+	//
+	outputFunction, err := output.OutputFunctionForName(outputFormat)
+	if err != nil {
+		return err
+	}
+	outputFunction(translationSet, outputDirPath)
+	return nil
+}
+
 func main() {
 	// Arguments
 	//
 	usage := `Sanat.
 
 Usage:
-  Sanat generate <input_file> <output_format> <output_dir> [-p value]
-  Sanat validate <input_file>
+  Sanat generate <input_file> <output_format> <output_dir> [-p value] [-l value] [--bundle-name value] [--package value] [--pseudo value] [--pseudo-expansion value] [--pseudo-rtl value] [--check | --watch]
+  Sanat validate <input_file> [-p value] [--specifier-width value]
+  Sanat serve <input_file> [-p value] [--package value] [--address value]
+  Sanat lsp [-p value]
 
 Options:
   -p --processors list  The preprocessors to use (comma-separated)
+  -l --languages list   The order in which to output languages
+                        (comma-separated; defaults to the order in which
+                        they appear in <input_file>)
+  --bundle-name name    The ResourceBundle base name used in the file names
+                        of the java-properties format [default: Messages]
+  --package name        The package (or namespace) of the generated kotlin,
+                        csharp, csharp-resw and go code
+  --pseudo lang         Add a pseudo-localized language (e.g. en-XA) generated
+                        from the first language
+  --pseudo-expansion percent
+                        How much longer (in percent) pseudo-localized
+                        values are than the originals [default: 30]
+  --pseudo-rtl lang     Add a right-to-left pseudo-localized language (e.g.
+                        ar-XB) generated from the first language
+  --check               Don't write anything; exit with an error (and print
+                        a diff) if the files in <output_dir> are out of date
+                        (files that are no longer generated are not reported)
+  --watch               Keep running, and generate the output again whenever
+                        <input_file> changes
+  --specifier-width chars
+                        The number of characters that format specifiers are
+                        estimated to take when checking maxlength limits
+                        [default: ` + strconv.Itoa(validation.DefaultSpecifierWidth) + `]
+  --address addr        The address to serve the output formats over HTTP
+                        at [default: localhost:8080]
+
+An <input_file> with a .json extension is read as the output of the json
+format. Use "-" as the <output_dir> to print the json format to stdout.
   `
 	args, _ := docopt.Parse(usage, nil, true, "Sanat", false)
 
@@ -46,8 +162,73 @@ Options:
 		}
 	}
 
+	// Language server for editors (communicating over stdin/stdout)
+	//
+	if args["lsp"].(bool) {
+		if err := lsp.New(preprocessor).Serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	inputFilePath := args["<input_file>"].(string)
 
+	setOutputOptions(args)
+	if args["generate"].(bool) {
+		if err := output.CheckOptionsForFormat(args["<output_format>"].(string)); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+
+	// Serve output formats over HTTP (the file is parsed when it
+	// changes, so parser errors are reported by the server)
+	//
+	if args["serve"].(bool) {
+		address := args["--address"].(string)
+		fmt.Fprintln(os.Stderr, "Serving", inputFilePath, "at http://"+address+"/")
+		if err := http.ListenAndServe(address, server.New(inputFilePath, preprocessor)); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Regenerate output whenever the translation file changes (parser
+	// errors are reported but don't stop the watching)
+	//
+	if args["generate"].(bool) && args["--watch"].(bool) {
+		if _, err := output.OutputFunctionForName(args["<output_format>"].(string)); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		regenerate := func() {
+			// The output functions panic if writing the files fails
+			// (e.g. if the output directory is removed), which shouldn't
+			// stop the watching either
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintln(os.Stderr, "ERROR:", r)
+				}
+			}()
+			translationSet, err := parser.TranslationSetFromFile(inputFilePath, preprocessor, parserErrorHandler)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR: Not regenerating output until the errors are fixed")
+				return
+			}
+			if err := generate(translationSet, args); err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err.Error())
+				return
+			}
+			fmt.Fprintln(os.Stderr, time.Now().Format("15:04:05"), "Generated", args["<output_dir>"].(string))
+		}
+		regenerate()
+		fmt.Fprintln(os.Stderr, "Watching", inputFilePath, "for changes (press Ctrl-C to stop)")
+		util.WatchFile(inputFilePath, watchInterval, watchQuietPeriod, nil, regenerate)
+		return
+	}
+
 	// Parse translation file
 	//
 	translationSet, err := parser.TranslationSetFromFile(inputFilePath, preprocessor, parserErrorHandler)
@@ -56,16 +237,26 @@ Options:
 	}
 
 	if args["generate"].(bool) {
-		outputDirPath := args["<output_dir>"].(string)
-		outputFormat := args["<output_format>"].(string)
+		if err := generate(translationSet, args); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err.Error())
+			os.Exit(1)
+		}
+	}
 
-		// Write output
-		//
-		outputFunction, err := output.OutputFunctionForName(outputFormat)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
+	// Check for problems that are not parser errors
+	//
+	if args["validate"].(bool) {
+		specifierWidth, err := strconv.Atoi(args["--specifier-width"].(string))
+		if err != nil || specifierWidth < 0 {
+			fmt.Fprintln(os.Stderr, "Invalid --specifier-width value:", args["--specifier-width"])
+			os.Exit(1)
+		}
+		problems := append(validation.BidiProblems(translationSet), validation.MaxLengthProblems(translationSet, specifierWidth)...)
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, "ERROR:", problem)
+		}
+		if 0 < len(problems) {
 			os.Exit(1)
 		}
-		outputFunction(translationSet, outputDirPath)
 	}
 }
@@ -0,0 +1,199 @@
+// Package server serves the output of a translation file over HTTP, in
+// all of the output formats, so that development builds of apps can
+// fetch up-to-date strings without being rebuilt.
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output"
+	"hasseg.org/sanat/parser"
+	"hasseg.org/sanat/preprocessing"
+)
+
+// Server is an http.Handler serving `/<format>/<path>`, where <format>
+// is one of output.FilesFunctionsByName and <path> one of the files it
+// produces (e.g. `/android/values-fi/strings.xml`.) The translation file
+// is parsed again whenever it has been modified.
+type Server struct {
+	InputFilePath string
+	Preprocessor  preprocessing.Preprocessor
+
+	mutex        sync.Mutex
+	modTime      time.Time
+	size         int64
+	set          model.TranslationSet
+	parserErrors []string
+}
+
+func New(inputFilePath string, preprocessor preprocessing.Preprocessor) *Server {
+	return &Server{InputFilePath: inputFilePath, Preprocessor: preprocessor}
+}
+
+// translationSet returns the parsed translation set (parsing the file
+// again if it has changed since the last time), or the errors that
+// parsing it produced.
+func (s *Server) translationSet() (model.TranslationSet, []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	info, err := os.Stat(s.InputFilePath)
+	if err != nil {
+		s.modTime = time.Time{}
+		return model.NewTranslationSet(), []string{err.Error()}
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.set, s.parserErrors
+	}
+
+	parserErrors := make([]string, 0)
+	set, _ := parser.TranslationSetFromFile(s.InputFilePath, s.Preprocessor, func(lineNumber int, message string) {
+		parserErrors = append(parserErrors, fmt.Sprintf("Line %d: %s", lineNumber, message))
+	})
+	s.set, s.parserErrors = set, parserErrors
+	s.modTime, s.size = info.ModTime(), info.Size()
+	if 0 < len(parserErrors) {
+		fmt.Fprintln(os.Stderr, "ERROR: Could not parse", s.InputFilePath)
+	} else {
+		fmt.Fprintln(os.Stderr, "Parsed", s.InputFilePath)
+	}
+	return s.set, s.parserErrors
+}
+
+// ETag returns the entity tag for a file with the given contents.
+func ETag(contents string) string {
+	sum := sha1.Sum([]byte(contents))
+	return "\"" + hex.EncodeToString(sum[:]) + "\""
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func contentTypeForPath(filePath string) string {
+	switch path.Ext(filePath) {
+	case ".xml", ".resx", ".resw", ".ts":
+		return "application/xml; charset=utf-8"
+	case ".json", ".arb", ".xcstrings":
+		return "application/json; charset=utf-8"
+	}
+	if contentType := mime.TypeByExtension(path.Ext(filePath)); strings.HasPrefix(contentType, "text/") {
+		return contentType
+	}
+	return "text/plain; charset=utf-8"
+}
+
+func writeHTMLPage(w http.ResponseWriter, statusCode int, title string, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(statusCode)
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>%s</title></head>\n<body>\n<h1>%s</h1>\n%s</body>\n</html>\n",
+		html.EscapeString(title), html.EscapeString(title), body)
+}
+
+func linkList(hrefs []string) string {
+	ret := "<ul>\n"
+	for _, href := range hrefs {
+		ret += "<li><a href=\"" + html.EscapeString(href) + "\">" + html.EscapeString(href) + "</a></li>\n"
+	}
+	return ret + "</ul>\n"
+}
+
+func sortedKeys(files map[string]string) []string {
+	ret := make([]string, 0, len(files))
+	for key := range files {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	set, parserErrors := s.translationSet()
+	if 0 < len(parserErrors) {
+		body := "<pre>"
+		for _, parserError := range parserErrors {
+			body += html.EscapeString(parserError) + "\n"
+		}
+		writeHTMLPage(w, http.StatusInternalServerError, "Errors in "+s.InputFilePath, body+"</pre>\n")
+		return
+	}
+
+	components := strings.SplitN(strings.TrimPrefix(path.Clean(r.URL.Path), "/"), "/", 2)
+	formatName := components[0]
+
+	// Index of the formats
+	if len(formatName) == 0 {
+		hrefs := make([]string, 0)
+		for name := range output.FilesFunctionsByName {
+			hrefs = append(hrefs, "/"+name+"/")
+		}
+		sort.Strings(hrefs)
+		writeHTMLPage(w, http.StatusOK, s.InputFilePath, linkList(hrefs))
+		return
+	}
+
+	filesFunction, err := output.FilesFunctionForName(formatName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err := output.CheckOptionsForFormat(formatName); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	files := filesFunction(set)
+
+	// Index of the files of a format
+	if len(components) == 1 {
+		hrefs := make([]string, 0)
+		for _, filePath := range sortedKeys(files) {
+			hrefs = append(hrefs, "/"+formatName+"/"+filePath)
+		}
+		writeHTMLPage(w, http.StatusOK, formatName, linkList(hrefs))
+		return
+	}
+
+	contents, exists := files[components[1]]
+	if !exists {
+		http.Error(w, "No file '"+components[1]+"' in the "+formatName+" format", http.StatusNotFound)
+		return
+	}
+	etag := ETag(contents)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeForPath(components[1]))
+	w.Header().Set("Content-Length", fmt.Sprint(len(contents)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write([]byte(contents))
+	}
+}
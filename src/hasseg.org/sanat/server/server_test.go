@@ -0,0 +1,92 @@
+package server_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/preprocessing"
+	"hasseg.org/sanat/server"
+)
+
+func writeInputFile(t *testing.T, filePath string, contents string, modTime time.Time) {
+	if err := ioutil.WriteFile(filePath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func get(handler http.Handler, path string, ifNoneMatch string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", path, nil)
+	if 0 < len(ifNoneMatch) {
+		request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestServer(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "sanat-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	inputFilePath := filepath.Join(dirPath, "input.sanat")
+	modTime := time.Now().Add(-time.Hour)
+	writeInputFile(t, inputFilePath, "  Hello\n    en = Hello\n    fi = Hei\n", modTime)
+
+	s := server.New(inputFilePath, preprocessing.NewNoOpPreprocessor())
+
+	// Indexes
+	response := get(s, "/", "")
+	assert.Equal(t, http.StatusOK, response.Code, "")
+	assert.Contains(t, response.Body.String(), `<a href="/android/">`, "")
+
+	response = get(s, "/android/", "")
+	assert.Equal(t, http.StatusOK, response.Code, "")
+	assert.Contains(t, response.Body.String(), `<a href="/android/values-fi/strings.xml">`, "")
+
+	// Files
+	response = get(s, "/android/values-fi/strings.xml", "")
+	assert.Equal(t, http.StatusOK, response.Code, "")
+	assert.Equal(t, "application/xml; charset=utf-8", response.Header().Get("Content-Type"), "")
+	assert.Contains(t, response.Body.String(), ">Hei</string>", "")
+	etag := response.Header().Get("ETag")
+	assert.Equal(t, server.ETag(response.Body.String()), etag, "")
+
+	response = get(s, "/android/values-fi/strings.xml", etag)
+	assert.Equal(t, http.StatusNotModified, response.Code, "")
+	assert.Equal(t, "", response.Body.String(), "")
+
+	assert.Equal(t, http.StatusNotFound, get(s, "/android/values-sv/strings.xml", "").Code, "")
+	assert.Equal(t, http.StatusNotFound, get(s, "/nonexistent/fi.json", "").Code, "")
+
+	// Changes to the input file
+	writeInputFile(t, inputFilePath, "  Hello\n    en = Hello\n    fi = Moi\n", modTime.Add(time.Minute))
+	response = get(s, "/android/values-fi/strings.xml", etag)
+	assert.Equal(t, http.StatusOK, response.Code, "")
+	assert.Contains(t, response.Body.String(), ">Moi</string>", "")
+	assert.NotEqual(t, etag, response.Header().Get("ETag"), "")
+
+	// Parser errors
+	writeInputFile(t, inputFilePath, "  Hello\n    en = Hello\nOops\n", modTime.Add(2*time.Minute))
+	response = get(s, "/android/values-fi/strings.xml", "")
+	assert.Equal(t, http.StatusInternalServerError, response.Code, "")
+	assert.Equal(t, "text/html; charset=utf-8", response.Header().Get("Content-Type"), "")
+	assert.True(t, strings.Contains(response.Body.String(), "Line 3:"), "")
+
+	// Input file removed
+	os.Remove(inputFilePath)
+	assert.Equal(t, http.StatusInternalServerError, get(s, "/", "").Code, "")
+}
@@ -0,0 +1,237 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContextLineCount = 3
+
+type diffOperation struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// linesWithTerminators splits s into lines, keeping the trailing
+// newline on each line so that a missing final newline is considered
+// a difference.
+func linesWithTerminators(s string) []string {
+	ret := make([]string, 0)
+	for 0 < len(s) {
+		i := strings.Index(s, "\n")
+		if i == -1 {
+			ret = append(ret, s)
+			break
+		}
+		ret = append(ret, s[:i+1])
+		s = s[i+1:]
+	}
+	return ret
+}
+
+func operationsForLines(kind byte, lines []string) []diffOperation {
+	ret := make([]diffOperation, 0, len(lines))
+	for _, line := range lines {
+		ret = append(ret, diffOperation{kind, line})
+	}
+	return ret
+}
+
+// diffOperations computes the shortest edit script that turns a into b
+// (Myers' algorithm, in its linear space variant: instead of keeping the
+// furthest reaching paths of every step for backtracking, the middle
+// point of the edit script is found by searching from both ends at
+// once, and the halves on either side of it are diffed recursively.)
+func diffOperations(a []string, b []string) []diffOperation {
+	// Common prefix and suffix
+	prefixLength := 0
+	for prefixLength < len(a) && prefixLength < len(b) && a[prefixLength] == b[prefixLength] {
+		prefixLength++
+	}
+	suffixLength := 0
+	for suffixLength < len(a)-prefixLength && suffixLength < len(b)-prefixLength &&
+		a[len(a)-1-suffixLength] == b[len(b)-1-suffixLength] {
+		suffixLength++
+	}
+
+	ret := operationsForLines(' ', a[:prefixLength])
+	middleA := a[prefixLength : len(a)-suffixLength]
+	middleB := b[prefixLength : len(b)-suffixLength]
+	if len(middleA) == 0 {
+		ret = append(ret, operationsForLines('+', middleB)...)
+	} else if len(middleB) == 0 {
+		ret = append(ret, operationsForLines('-', middleA)...)
+	} else {
+		x, y := diffMiddlePoint(middleA, middleB)
+		ret = append(ret, diffOperations(middleA[:x], middleB[:y])...)
+		ret = append(ret, diffOperations(middleA[x:], middleB[y:])...)
+	}
+	return append(ret, operationsForLines(' ', a[len(a)-suffixLength:])...)
+}
+
+// diffMiddlePoint returns a point (x, y) through which a shortest edit
+// script from a to b passes, such that it is neither (0, 0) nor
+// (len(a), len(b)). a and b must not be empty, and must not have a
+// common prefix or suffix.
+func diffMiddlePoint(a []string, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	delta := n - m
+	checkInForwardSearch := delta%2 != 0
+
+	// The furthest reaching x on each diagonal k, searching forward from
+	// (0, 0) and backward from (n, m) (where x counts from the end.)
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	// Diagonals that have run past the end of a or b are not searched
+	// further; these are the numbers of such diagonals on either side
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			x := 0
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if n < x {
+				forwardEnd += 2
+			} else if m < y {
+				forwardStart += 2
+			} else if checkInForwardSearch {
+				backwardIndex := offset + delta - k
+				if 0 <= backwardIndex && backwardIndex < len(backward) && backward[backwardIndex] != -1 &&
+					n-backward[backwardIndex] <= x {
+					return x, y
+				}
+			}
+		}
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			x := 0
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if n < x {
+				backwardEnd += 2
+			} else if m < y {
+				backwardStart += 2
+			} else if !checkInForwardSearch {
+				forwardIndex := offset + delta - k
+				if 0 <= forwardIndex && forwardIndex < len(forward) && forward[forwardIndex] != -1 &&
+					n-x <= forward[forwardIndex] {
+					forwardX := forward[forwardIndex]
+					return forwardX, forwardX - (forwardIndex - offset)
+				}
+			}
+		}
+	}
+
+	// Only reached if a and b have nothing in common
+	return n, 0
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// UnifiedDiff returns a unified diff (with three lines of context)
+// describing how to turn `from` into `to`, or an empty string if they
+// are equal.
+func UnifiedDiff(fromName string, toName string, from string, to string) string {
+	if from == to {
+		return ""
+	}
+	ops := diffOperations(linesWithTerminators(from), linesWithTerminators(to))
+
+	ret := "--- " + fromName + "\n+++ " + toName + "\n"
+
+	i := 0
+	for i < len(ops) {
+		// Find the next change
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if len(ops) <= i {
+			break
+		}
+
+		// Changes separated by only a few unchanged lines are
+		// merged into the same hunk
+		hunkStart := i - diffContextLineCount
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		lastChange := i
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				if 2*diffContextLineCount < j-lastChange-1 {
+					break
+				}
+				lastChange = j
+			}
+		}
+		hunkEnd := lastChange + 1 + diffContextLineCount
+		if len(ops) < hunkEnd {
+			hunkEnd = len(ops)
+		}
+
+		// Line numbers at the start of the hunk
+		fromStart, toStart := 0, 0
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				fromStart++
+			}
+			if op.kind != '-' {
+				toStart++
+			}
+		}
+		fromCount, toCount := 0, 0
+		body := ""
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				fromCount++
+			}
+			if op.kind != '-' {
+				toCount++
+			}
+			body += string(op.kind) + op.line
+			if !strings.HasSuffix(op.line, "\n") {
+				body += "\n\\ No newline at end of file\n"
+			}
+		}
+
+		ret += "@@ -" + hunkRange(fromStart, fromCount) + " +" + hunkRange(toStart, toCount) + " @@\n" + body
+		i = hunkEnd
+	}
+
+	return ret
+}
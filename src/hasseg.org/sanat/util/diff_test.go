@@ -0,0 +1,55 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/util"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	ass := func(expected string, from string, to string) {
+		assert.Equal(t, expected, util.UnifiedDiff("a", "b", from, to), from+" → "+to)
+	}
+
+	// Equal inputs
+	ass("", "", "")
+	ass("", "foo\nbar\n", "foo\nbar\n")
+
+	// Changed line
+	ass("--- a\n+++ b\n@@ -1,3 +1,3 @@\n foo\n-bar\n+baz\n qux\n",
+		"foo\nbar\nqux\n", "foo\nbaz\nqux\n")
+
+	// Added and removed lines
+	ass("--- a\n+++ b\n@@ -1,2 +1,3 @@\n foo\n+bar\n qux\n",
+		"foo\nqux\n", "foo\nbar\nqux\n")
+	ass("--- a\n+++ b\n@@ -1,3 +1,2 @@\n foo\n-bar\n qux\n",
+		"foo\nbar\nqux\n", "foo\nqux\n")
+
+	// Diffing against an empty (missing) file
+	ass("--- a\n+++ b\n@@ -0,0 +1,2 @@\n+foo\n+bar\n", "", "foo\nbar\n")
+
+	// Large inputs
+	large := strings.Repeat("line\n", 5000)
+	assert.True(t, strings.HasPrefix(util.UnifiedDiff("a", "b", "", large), "--- a\n+++ b\n@@ -0,0 +1,5000 @@\n+line\n"), "")
+	assert.True(t, strings.HasPrefix(util.UnifiedDiff("a", "b", large, strings.Repeat("other\n", 5000)), "--- a\n+++ b\n@@ -1,5000 +1,5000 @@\n-line\n"), "")
+
+	// Missing trailing newline
+	ass("--- a\n+++ b\n@@ -1 +1 @@\n-foo\n\\ No newline at end of file\n+foo\n",
+		"foo", "foo\n")
+
+	// Context is limited to three lines; distant changes get separate hunks
+	ass("--- a\n+++ b\n"+
+		"@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n 4\n"+
+		"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+Y\n",
+		"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		"X\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\nY\n")
+
+	// ...but nearby changes share a hunk
+	ass("--- a\n+++ b\n"+
+		"@@ -1,6 +1,6 @@\n-1\n+X\n 2\n 3\n 4\n-5\n+Y\n 6\n",
+		"1\n2\n3\n4\n5\n6\n",
+		"X\n2\n3\n4\nY\n6\n")
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+// WriteFiles writes the given file contents (keyed by path relative to
+// dirPath) to disk, creating intermediate directories as needed.
+func WriteFiles(dirPath string, files map[string]string) {
+	for relativePath, contents := range files {
+		filePath := path.Join(dirPath, relativePath)
+		os.MkdirAll(path.Dir(filePath), 0777)
+
+		f, err := os.Create(filePath)
+		if err != nil {
+			panic(err)
+		}
+
+		_, err = f.WriteString(contents)
+		f.Close()
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// FileContents returns the contents of the file at filePath, and whether
+// the file could be read at all.
+func FileContents(filePath string) (string, bool) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"regexp"
+)
+
+// CharacterEntityRegexp matches HTML/XML character entities (e.g.
+// `&amp;` or `&#228;`.)
+var CharacterEntityRegexp = regexp.MustCompile(`&#?[A-Za-z0-9]+;`)
+
+// MarkupTagRegexp matches HTML/XML tags (e.g. `<a href="x">` or `</a>`.)
+var MarkupTagRegexp = regexp.MustCompile(`<[^<>]*>`)
+
+// HTML/XML tags and character entities (e.g. from the markdown
+// preprocessor)
+var markupRegexp = regexp.MustCompile(MarkupTagRegexp.String() + `|` + CharacterEntityRegexp.String())
+
+// MapNonMarkupParts calls transform for the parts of text that are not
+// markup, and returns text with them replaced by the return values.
+func MapNonMarkupParts(text string, transform func(string) string) string {
+	ret := ""
+	start := 0
+	for _, loc := range markupRegexp.FindAllStringIndex(text, -1) {
+		ret += transform(text[start:loc[0]]) + text[loc[0]:loc[1]]
+		start = loc[1]
+	}
+	return ret + transform(text[start:])
+}
@@ -0,0 +1,21 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/util"
+)
+
+func TestMapNonMarkupParts(t *testing.T) {
+	parts := make([]string, 0)
+	ret := util.MapNonMarkupParts(`a <b class="x">bold</b> &amp; &#39;c&#39;`, func(s string) string {
+		parts = append(parts, s)
+		return strings.ToUpper(s)
+	})
+	assert.Equal(t, `A <b class="x">BOLD</b> &amp; &#39;C&#39;`, ret, "")
+	assert.Equal(t, []string{"a ", "bold", " ", " ", "c", ""}, parts, "")
+	assert.Equal(t, "A < B", util.MapNonMarkupParts("a < b", strings.ToUpper), "")
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// OrderedMap is a string-keyed map that remembers the order in which
+// its keys were added. Values are typically strings or nested
+// *OrderedMaps.
+type OrderedMap struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{Keys: make([]string, 0), Values: make(map[string]interface{})}
+}
+
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if _, exists := m.Values[key]; !exists {
+		m.Keys = append(m.Keys, key)
+	}
+	m.Values[key] = value
+}
+
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, exists := m.Values[key]
+	return value, exists
+}
+
+// SetAtPath sets a value in a tree of nested OrderedMaps, creating the
+// intermediate maps as needed. It fails if the path would need to pass
+// through (or replace) a value that is not a nested map, or replace a
+// nested map with a value.
+func (m *OrderedMap) SetAtPath(path []string, value interface{}) error {
+	current := m
+	for i, key := range path {
+		existing, exists := current.Get(key)
+		if i == len(path)-1 {
+			if exists {
+				return errors.New("'" + strings.Join(path, ".") + "' is already defined")
+			}
+			current.Set(key, value)
+			break
+		}
+		if !exists {
+			nested := NewOrderedMap()
+			current.Set(key, nested)
+			current = nested
+			continue
+		}
+		nested, isMap := existing.(*OrderedMap)
+		if !isMap {
+			return errors.New("'" + strings.Join(path[:i+1], ".") + "' is defined as a value and cannot contain '" + strings.Join(path, ".") + "'")
+		}
+		current = nested
+	}
+	return nil
+}
+
+func jsonValue(value interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+// MarshalJSON encodes the map as a JSON object whose members are in
+// insertion order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, key := range m.Keys {
+		if 0 < i {
+			b.WriteString(",")
+		}
+		keyJSON, err := jsonValue(key)
+		if err != nil {
+			return nil, err
+		}
+		valueJSON, err := jsonValue(m.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(keyJSON)
+		b.WriteString(":")
+		b.Write(valueJSON)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// IndentedJSON returns the given value as indented JSON (without
+// escaping HTML special characters), ending with a newline.
+func IndentedJSON(value interface{}) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
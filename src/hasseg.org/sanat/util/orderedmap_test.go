@@ -0,0 +1,35 @@
+package util_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/util"
+)
+
+func TestOrderedMapJSONKeepsInsertionOrder(t *testing.T) {
+	m := util.NewOrderedMap()
+	m.Set("b", "1")
+	m.Set("a", "<2>")
+	m.Set("b", "3")
+
+	assert.Equal(t, []string{"b", "a"}, m.Keys, "")
+	assert.Equal(t, "{\n  \"b\": \"3\",\n  \"a\": \"<2>\"\n}\n", util.IndentedJSON(m), "")
+}
+
+func TestOrderedMapSetAtPath(t *testing.T) {
+	m := util.NewOrderedMap()
+	assert.Nil(t, m.SetAtPath([]string{"LoginView", "Title"}, "Log in"), "")
+	assert.Nil(t, m.SetAtPath([]string{"LoginView", "Button"}, "OK"), "")
+	assert.Nil(t, m.SetAtPath([]string{"Other"}, "x"), "")
+	b, err := json.Marshal(m)
+	assert.Nil(t, err, "")
+	assert.Equal(t, `{"LoginView":{"Title":"Log in","Button":"OK"},"Other":"x"}`, string(b), "")
+
+	// Conflicts
+	assert.NotNil(t, m.SetAtPath([]string{"LoginView"}, "y"), "Replacing a nested map")
+	assert.NotNil(t, m.SetAtPath([]string{"Other", "Sub"}, "y"), "Passing through a value")
+	assert.NotNil(t, m.SetAtPath([]string{"Other"}, "y"), "Redefining a value")
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"strings"
+	"unicode"
 )
 
 func ComponentsFromCommaSeparatedList(text string) []string {
@@ -42,3 +43,26 @@ func TrailingWhitespace(s string) string {
 	}
 	return ret
 }
+
+// CamelCaseIdentifier turns s into a camel-cased identifier containing
+// only ASCII letters and digits: characters other than those separate
+// words, whose first letters are capitalized. The first letter of the
+// whole identifier is capitalized if upperFirst is true and lowercased
+// otherwise. Identifiers that would begin with a digit are prefixed
+// with "key".
+func CamelCaseIdentifier(s string, upperFirst bool) string {
+	isIdentifierChar := func(c rune) bool {
+		return c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c))
+	}
+	ret := ""
+	for _, word := range strings.FieldsFunc(s, func(c rune) bool { return !isIdentifierChar(c) }) {
+		ret += strings.ToUpper(word[:1]) + word[1:]
+	}
+	if len(ret) == 0 || unicode.IsDigit(rune(ret[0])) {
+		ret = "Key" + ret
+	}
+	if !upperFirst {
+		ret = strings.ToLower(ret[:1]) + ret[1:]
+	}
+	return ret
+}
@@ -56,3 +56,19 @@ func TestTrailingWhitespace(t *testing.T) {
 	ass(" \t ", "Moro \t ")
 	ass("", "Moro  xx")
 }
+
+func TestCamelCaseIdentifier(t *testing.T) {
+	ass := func(expectedLower string, expectedUpper string, given string) {
+		assert.Equal(t, expectedLower, util.CamelCaseIdentifier(given, false), given)
+		assert.Equal(t, expectedUpper, util.CamelCaseIdentifier(given, true), given)
+	}
+
+	ass("foo", "Foo", "foo")
+	ass("loginViewTitle", "LoginViewTitle", "LoginView.Title")
+	ass("loginViewTitle", "LoginViewTitle", "login_view title")
+	ass("fooBar2", "FooBar2", "Foo-bar-2")
+	ass("key2Foo", "Key2Foo", "2 foo")
+	ass("key", "Key", "")
+	ass("key", "Key", "...")
+	ass("hyv", "Hyv", "Hyvä") // Non-ASCII letters are dropped
+}
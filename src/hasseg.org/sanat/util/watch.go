@@ -0,0 +1,73 @@
+package util
+
+import (
+	"os"
+	"time"
+)
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func fileStateForPath(filePath string) fileState {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// fileWatcher decides when changes to a file are reported, given its
+// state at successive points in time.
+type fileWatcher struct {
+	quietPeriod    time.Duration
+	reportedState  fileState
+	lastState      fileState
+	lastChangeTime time.Time
+}
+
+func newFileWatcher(initialState fileState, quietPeriod time.Duration) *fileWatcher {
+	return &fileWatcher{quietPeriod: quietPeriod, reportedState: initialState, lastState: initialState}
+}
+
+// update records the state of the file at the time now, and returns
+// whether a change should be reported: the file exists and is different
+// from when a change was last reported (or from its initial state), and
+// has stayed the same for at least the quiet period.
+func (w *fileWatcher) update(now time.Time, state fileState) bool {
+	if state != w.lastState {
+		w.lastState = state
+		w.lastChangeTime = now
+		return false
+	}
+	if state.exists && state != w.reportedState && w.quietPeriod <= now.Sub(w.lastChangeTime) {
+		w.reportedState = state
+		return true
+	}
+	return false
+}
+
+// WatchFile polls the modification time and size of the file at filePath
+// every interval, and calls changed once the file has changed and then
+// stayed the same for at least quietPeriod (so that a burst of rapid
+// saves only causes one call.) A file that disappears (e.g. while an
+// editor replaces it) is not considered changed until it reappears.
+// Returns when stop is closed.
+func WatchFile(filePath string, interval time.Duration, quietPeriod time.Duration, stop <-chan struct{}, changed func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	watcher := newFileWatcher(fileStateForPath(filePath), quietPeriod)
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if watcher.update(now, fileStateForPath(filePath)) {
+				changed()
+			}
+		}
+	}
+}
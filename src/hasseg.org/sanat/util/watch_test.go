@@ -0,0 +1,61 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWatcher(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(milliseconds int) time.Time {
+		return start.Add(time.Duration(milliseconds) * time.Millisecond)
+	}
+	stateWithSize := func(size int64) fileState {
+		return fileState{exists: true, modTime: start, size: size}
+	}
+
+	watcher := newFileWatcher(stateWithSize(1), 300*time.Millisecond)
+	assert.False(t, watcher.update(at(200), stateWithSize(1)), "Not reported before anything changes")
+	assert.False(t, watcher.update(at(1000), stateWithSize(1)), "")
+
+	// A burst of rapid edits is reported once, after the quiet period
+	assert.False(t, watcher.update(at(1200), stateWithSize(2)), "")
+	assert.False(t, watcher.update(at(1400), stateWithSize(3)), "")
+	assert.False(t, watcher.update(at(1600), stateWithSize(4)), "")
+	assert.False(t, watcher.update(at(1800), stateWithSize(4)), "Quiet period hasn't passed since the last edit")
+	assert.True(t, watcher.update(at(2000), stateWithSize(4)), "")
+	assert.False(t, watcher.update(at(2200), stateWithSize(4)), "Only reported once")
+
+	// Removing the file is not a change, but the file reappearing is
+	assert.False(t, watcher.update(at(3000), fileState{}), "")
+	assert.False(t, watcher.update(at(4000), fileState{}), "")
+	assert.False(t, watcher.update(at(4200), stateWithSize(5)), "")
+	assert.True(t, watcher.update(at(4600), stateWithSize(5)), "")
+
+	// ...unless it reappears as it was
+	assert.False(t, watcher.update(at(5000), fileState{}), "")
+	assert.False(t, watcher.update(at(5200), stateWithSize(5)), "")
+	assert.False(t, watcher.update(at(6000), stateWithSize(5)), "")
+}
+
+func TestWatchFileStops(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "sanat-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		WatchFile(filepath.Join(dirPath, "input.sanat"), time.Millisecond, time.Millisecond, stop, func() {})
+		close(done)
+	}()
+	close(stop)
+	<-done
+}
@@ -0,0 +1,128 @@
+package validation
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/util"
+)
+
+// RTLLanguages are the (primary subtags of the) right-to-left languages
+// whose values BidiProblems checks.
+var RTLLanguages = []string{"ar", "he", "fa"}
+
+// Bidi isolate characters
+const (
+	leftToRightIsolate    = '\u2066'
+	rightToLeftIsolate    = '\u2067'
+	firstStrongIsolate    = '\u2068'
+	popDirectionalIsolate = '\u2069'
+)
+
+func isRTLLanguage(language string) bool {
+	primarySubtag := strings.ToLower(language)
+	if separatorIndex := strings.IndexAny(primarySubtag, "-_"); separatorIndex != -1 {
+		primarySubtag = primarySubtag[:separatorIndex]
+	}
+	for _, rtlLanguage := range RTLLanguages {
+		if primarySubtag == rtlLanguage {
+			return true
+		}
+	}
+	return false
+}
+
+func isStrongRTL(r rune) bool {
+	return unicode.In(r, unicode.Arabic, unicode.Hebrew, unicode.Syriac, unicode.Thaana, unicode.Nko)
+}
+
+func isLatinLetter(r rune) bool {
+	return unicode.IsLetter(r) && unicode.Is(unicode.Latin, r)
+}
+
+// bidiChecker walks through the segments of a value, keeping track of
+// the bidi isolates that are open.
+type bidiChecker struct {
+	isolateDepth  int
+	latinFragment string // Latin text outside isolates not yet reported
+	problems      []string
+}
+
+func (c *bidiChecker) endLatinFragment() {
+	fragment := strings.TrimRightFunc(c.latinFragment, func(r rune) bool {
+		return !isLatinLetter(r) && !unicode.IsDigit(r)
+	})
+	if 0 < len(fragment) {
+		c.problems = append(c.problems, "Latin text '"+fragment+"' is not bidi-isolated")
+	}
+	c.latinFragment = ""
+}
+
+func (c *bidiChecker) checkText(text string) {
+	for _, r := range text {
+		switch {
+		case r == leftToRightIsolate || r == rightToLeftIsolate || r == firstStrongIsolate:
+			c.endLatinFragment()
+			c.isolateDepth++
+		case r == popDirectionalIsolate:
+			if 0 < c.isolateDepth {
+				c.isolateDepth--
+			}
+		case 0 < c.isolateDepth:
+		case 0 < len(c.latinFragment):
+			if isStrongRTL(r) || r == '\n' {
+				c.endLatinFragment()
+			} else {
+				c.latinFragment += string(r)
+			}
+		case isLatinLetter(r):
+			c.latinFragment = string(r)
+		}
+	}
+}
+
+func (c *bidiChecker) checkFormatSpecifier(position int) {
+	c.endLatinFragment()
+	if c.isolateDepth == 0 {
+		c.problems = append(c.problems, "Format specifier "+strconv.Itoa(position+1)+" is not bidi-isolated (wrap it in FSI … PDI, i.e. U+2068 … U+2069)")
+	}
+}
+
+// BidiProblems returns the values of right-to-left languages (see
+// RTLLanguages) that contain format specifiers or Latin text outside
+// bidi isolates (LRI/RLI/FSI … PDI). Without them the interpolated
+// values (e.g. numbers) and the Latin text can be displayed in the
+// wrong order relative to the surrounding text. Markup is not checked.
+func BidiProblems(set model.TranslationSet) []Problem {
+	ret := make([]Problem, 0)
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			for _, value := range translation.Values {
+				if !isRTLLanguage(value.Language) {
+					continue
+				}
+				checker := bidiChecker{}
+				position := 0
+				for _, segment := range value.Segments {
+					switch segment.(type) {
+					case model.TextSegment:
+						util.MapNonMarkupParts(segment.(model.TextSegment).Text, func(s string) string {
+							checker.checkText(s)
+							return s
+						})
+					case model.FormatSpecifierSegment:
+						checker.checkFormatSpecifier(position)
+						position++
+					}
+				}
+				checker.endLatinFragment()
+				for _, message := range checker.problems {
+					ret = append(ret, Problem{Key: translation.Key, Language: value.Language, Message: message})
+				}
+			}
+		}
+	}
+	return ret
+}
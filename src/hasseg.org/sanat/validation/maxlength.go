@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/output/dump"
+	"hasseg.org/sanat/util"
+)
+
+// DefaultSpecifierWidth is the default estimate for the number of
+// characters that a format specifier is replaced with.
+const DefaultSpecifierWidth = 10
+
+// RenderedLength returns the estimated number of characters in the
+// displayed text of the segments: markup tags are not counted, character
+// entities count as one character, and format specifiers count as
+// specifierWidth characters.
+func RenderedLength(segments []model.Segment, specifierWidth int) int {
+	ret := 0
+	for _, segment := range segments {
+		switch segment.(type) {
+		case model.TextSegment:
+			// Entities in the text between the tags (but not in the
+			// attributes of the tags) are displayed as one character
+			for _, part := range util.MarkupTagRegexp.Split(segment.(model.TextSegment).Text, -1) {
+				ret += utf8.RuneCountInString(util.CharacterEntityRegexp.ReplaceAllString(part, "&"))
+			}
+		case model.FormatSpecifierSegment:
+			ret += specifierWidth
+		}
+	}
+	return ret
+}
+
+var platforms = []model.TranslationPlatform{
+	model.PlatformApple,
+	model.PlatformAndroid,
+	model.PlatformWindows,
+	model.PlatformJava,
+	model.PlatformWeb,
+}
+
+// MaxLengthProblems returns the values whose RenderedLength is greater
+// than the maximum length of their translation, or than the maximum
+// length on one of the platforms that the translation is for.
+func MaxLengthProblems(set model.TranslationSet, specifierWidth int) []Problem {
+	ret := make([]Problem, 0)
+	for _, section := range set.Sections {
+		for _, translation := range section.Translations {
+			if translation.MaxLength == 0 && len(translation.PlatformMaxLengths) == 0 {
+				continue
+			}
+
+			// The platforms with their own maximum lengths are checked
+			// separately
+			usesDefaultMaxLength := false
+			overridingPlatforms := make([]model.TranslationPlatform, 0)
+			for _, platform := range platforms {
+				if !translation.IsForPlatform(platform) {
+					continue
+				}
+				if _, ok := translation.PlatformMaxLengths[platform]; ok {
+					overridingPlatforms = append(overridingPlatforms, platform)
+				} else {
+					usesDefaultMaxLength = true
+				}
+			}
+
+			for _, value := range translation.Values {
+				length := RenderedLength(value.Segments, specifierWidth)
+				description := fmt.Sprintf("Value is %d characters long", length)
+				if 0 < len(value.Arguments()) {
+					description += fmt.Sprintf(" (counting format specifiers as %d)", specifierWidth)
+				}
+
+				if usesDefaultMaxLength && 0 < translation.MaxLength && translation.MaxLength < length {
+					ret = append(ret, Problem{
+						Key:      translation.Key,
+						Language: value.Language,
+						Message:  fmt.Sprintf("%s; the maximum is %d", description, translation.MaxLength),
+					})
+				}
+				for _, platform := range overridingPlatforms {
+					if maxLength := translation.PlatformMaxLengths[platform]; maxLength < length {
+						ret = append(ret, Problem{
+							Key:      translation.Key,
+							Language: value.Language,
+							Message:  fmt.Sprintf("%s; the maximum on %s is %d", description, dump.StringForPlatform(platform), maxLength),
+						})
+					}
+				}
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,18 @@
+// Package validation checks translation sets for problems that are not
+// parser errors but would cause bugs in the apps that use them.
+package validation
+
+import (
+	"fmt"
+)
+
+// Problem is an issue in the value of a translation for a language.
+type Problem struct {
+	Key      string
+	Language string
+	Message  string
+}
+
+func (problem Problem) String() string {
+	return fmt.Sprintf("Translation '%s' (%s): %s", problem.Key, problem.Language, problem.Message)
+}
@@ -0,0 +1,102 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"hasseg.org/sanat/model"
+	"hasseg.org/sanat/parser"
+	"hasseg.org/sanat/preprocessing"
+	"hasseg.org/sanat/validation"
+)
+
+func problemStrings(problems []validation.Problem) []string {
+	ret := make([]string, 0)
+	for _, problem := range problems {
+		ret = append(ret, problem.String())
+	}
+	return ret
+}
+
+func TestBidiProblems(t *testing.T) {
+	bidiControls := strings.NewReplacer("[LRI]", "\u2066", "[RLI]", "\u2067", "[FSI]", "\u2068", "[PDI]", "\u2069", "[RLM]", "\u200F")
+	set, err := parser.TranslationSetFromReader(strings.NewReader(bidiControls.Replace(`
+  Isolated
+    en = You have {d} new messages in Gmail
+    ar = لديك [FSI]{d}[PDI] رسائل جديدة في [FSI]Gmail[PDI]
+    he = יש לך [LRI]{d}[PDI] הודעות חדשות ב־[RLI][RLM]Gmail[PDI]
+  NotIsolated
+    en = You have {d} new messages in Gmail
+    ar = لديك {d} رسائل جديدة في Google Play، شكرا
+    fa-IR = شما {1:d} پیام {2:s} دارید
+    fi = Sinulla on {d} uutta viestiä
+  Markup
+    ar_EG = <a href="https://example.com">مرحبا</a> 123
+  Nested
+    he = [FSI][LRI]a[PDI] {d}[PDI] b
+`)), preprocessing.NewNoOpPreprocessor(), nil)
+	assert.Nil(t, err, "")
+
+	assert.Equal(t, []string{
+		"Translation 'NotIsolated' (ar): Format specifier 1 is not bidi-isolated (wrap it in FSI … PDI, i.e. U+2068 … U+2069)",
+		"Translation 'NotIsolated' (ar): Latin text 'Google Play' is not bidi-isolated",
+		"Translation 'NotIsolated' (fa-IR): Format specifier 1 is not bidi-isolated (wrap it in FSI … PDI, i.e. U+2068 … U+2069)",
+		"Translation 'NotIsolated' (fa-IR): Format specifier 2 is not bidi-isolated (wrap it in FSI … PDI, i.e. U+2068 … U+2069)",
+		"Translation 'Nested' (he): Latin text 'b' is not bidi-isolated",
+	}, problemStrings(validation.BidiProblems(set)), "")
+}
+
+func TestRenderedLength(t *testing.T) {
+	ass := func(expected int, segments ...model.Segment) {
+		assert.Equal(t, expected, validation.RenderedLength(segments, 10), "")
+	}
+	specifier := model.NewFormatSpecifierSegment(model.DataTypeString, -1, -1)
+
+	ass(0)
+	ass(5, model.NewTextSegment("Hello"))
+	ass(5, model.NewTextSegment("Hällö"))
+	ass(17, model.NewTextSegment("Hi, "), specifier, model.NewTextSegment("!!!"))
+	ass(11, model.NewTextSegment(`<a href="x">Tom &amp; Jerry</a>`))
+	ass(11, model.NewTextSegment(`<a title="&amp;">Tom &amp; Jerry</a>`))
+}
+
+func TestMaxLengthProblems(t *testing.T) {
+	set, err := parser.TranslationSetFromReader(strings.NewReader(`
+  Button
+    maxlength = 10
+    maxlength.android = 5
+    en = Continue
+    fi = Jatka eteenpäin
+    sv = Fortsätt
+  Title
+    platforms = android
+    maxlength = 5
+    maxlength.android = 20
+    en = Hello, {s}
+  Greeting
+    maxlength.web = 8
+    en = Hello, {s}
+  Unlimited
+    en = Lorem ipsum dolor sit amet, consectetur adipiscing elit
+`), preprocessing.NewNoOpPreprocessor(), nil)
+	assert.Nil(t, err, "")
+
+	assert.Equal(t, []string{
+		"Translation 'Button' (en): Value is 8 characters long; the maximum on Android is 5",
+		"Translation 'Button' (fi): Value is 15 characters long; the maximum is 10",
+		"Translation 'Button' (fi): Value is 15 characters long; the maximum on Android is 5",
+		"Translation 'Button' (sv): Value is 8 characters long; the maximum on Android is 5",
+		"Translation 'Greeting' (en): Value is 17 characters long (counting format specifiers as 10); the maximum on Web is 8",
+	}, problemStrings(validation.MaxLengthProblems(set, 10)), "")
+
+	assert.Equal(t, []string{
+		"Translation 'Button' (en): Value is 8 characters long; the maximum on Android is 5",
+		"Translation 'Button' (fi): Value is 15 characters long; the maximum is 10",
+		"Translation 'Button' (fi): Value is 15 characters long; the maximum on Android is 5",
+		"Translation 'Button' (sv): Value is 8 characters long; the maximum on Android is 5",
+		"Translation 'Title' (en): Value is 27 characters long (counting format specifiers as 20); the maximum on Android is 20",
+		"Translation 'Greeting' (en): Value is 27 characters long (counting format specifiers as 20); the maximum on Web is 8",
+	}, problemStrings(validation.MaxLengthProblems(set, 20)), "The specifier width is configurable")
+}